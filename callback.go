@@ -0,0 +1,50 @@
+package rgo
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/uluyol/rgo/internal/rname"
+)
+
+// Callback is a Go function that can be called from R. R passes
+// all of its arguments flattened into a single numeric vector and
+// receives the returned slice as a numeric vector. A non-nil error
+// is raised in R as an error with the same message.
+type Callback func(args []float64) ([]float64, error)
+
+const callbackStr = `%s <- function(...) {
+	r <- fromJSON(httpPOST("http://localhost:%d/%s", postfields=toJSON(as.double(c(...)), digits=NA)))
+	if (r$error != "") {
+		stop(r$error)
+	}
+	as.double(r$result)
+}`
+
+// callbackStubStr replaces callbacks in transcripts.
+const callbackStubStr = `%s <- function(...) stop("Go callback %s is not available outside of rgo")`
+
+// Register defines an R function called name that synchronously
+// calls fn. This allows R code (e.g. the objective passed to optim)
+// to compute values in Go.
+//
+// name must be a syntactic R name, e.g. "my.callback".
+//
+// fn runs while R is blocked waiting for its result, so it must not
+// use the Conn.
+func (c *Conn) Register(name string, fn Callback) error {
+	if c.err != nil {
+		return c.err
+	}
+	if !rname.Valid(name) {
+		return errors.Errorf("invalid callback name %q: must be a syntactic R name", name)
+	}
+	key := "go.cb." + name
+	c.server.putCallback(key, fn)
+	cmd := fmt.Sprintf(callbackStr, name, c.server.port, key)
+	err := c.exec(cmd, fmt.Sprintf(callbackStubStr, name, name))
+	if err != nil && !IsWarning(err) {
+		c.server.rmCallback(key)
+	}
+	return errors.Wrapf(err, "failed to define callback %q in R", name)
+}
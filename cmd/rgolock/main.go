@@ -0,0 +1,61 @@
+// Command rgolock writes a lockfile for R packages that can be used
+// with rgo.WithLockfile.
+//
+// Usage:
+//
+//	rgolock [-o file] [package ...]
+//
+// The given packages are loaded and locked. If none are given, the
+// packages that rgo itself loads are locked.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/uluyol/rgo"
+)
+
+func main() {
+	out := flag.String("o", "", "write the lockfile to `file` instead of stdout")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: rgolock [-o file] [package ...]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if err := run(*out, flag.Args()); err != nil {
+		fmt.Fprintf(os.Stderr, "rgolock: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(out string, pkgs []string) error {
+	c, err := rgo.Connection()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	if err := c.Require(pkgs...); err != nil {
+		return err
+	}
+	l, err := c.Lockfile()
+	if err != nil {
+		return err
+	}
+	var w io.Writer = os.Stdout
+	if out != "" {
+		f, err := os.Create(out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	if _, err := fmt.Fprintln(w, "# Generated by rgolock"); err != nil {
+		return err
+	}
+	_, err = l.WriteTo(w)
+	return err
+}
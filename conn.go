@@ -9,6 +9,8 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/uluyol/rgo/dataframe"
@@ -32,6 +34,17 @@ type Conn struct {
 	strict  bool
 	closed  <-chan struct{}
 	waitErr error
+
+	debug      bool
+	output     *outputState
+	hooks      []Hook
+	transcript *scriptWriter
+	installer  *Installer
+
+	// bytesSent and bytesRecv count the data transferred since
+	// the last command completed. bytesRecv is accessed atomically.
+	bytesSent int64
+	bytesRecv int64
 }
 
 func (c *Conn) isClosed() bool {
@@ -65,7 +78,12 @@ func (c *Conn) start() error {
 }
 
 type connConfig struct {
-	debug bool
+	debug      bool
+	output     OutputHandler
+	hooks      []Hook
+	transcript string
+	installer  *Installer
+	lockfile   string
 }
 
 type ConnOption func(*connConfig)
@@ -85,6 +103,13 @@ func Connection(opts ...ConnOption) (*Conn, error) {
 		opt(&cfg)
 	}
 	var c Conn
+	var lock *Lockfile
+	if cfg.lockfile != "" {
+		var err error
+		if lock, err = readLockfileAt(cfg.lockfile); err != nil {
+			return nil, err
+		}
+	}
 	out, err := exec.Command("R", "--no-save", "-s", "-e", checkDepsCmd).CombinedOutput()
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to check dependencies")
@@ -95,7 +120,15 @@ func Connection(opts ...ConnOption) (*Conn, error) {
 		}
 		return nil, depError{[]string{"jsonlite", "RCurl"}}
 	}
-	c.cmd = exec.Command("R", "--no-save")
+	c.debug = cfg.debug
+	c.hooks = cfg.hooks
+	c.installer = cfg.installer
+	if cfg.output != nil {
+		c.output = newOutputState(cfg.output)
+		c.cmd = exec.Command("R", "--no-save", "--slave")
+	} else {
+		c.cmd = exec.Command("R", "--no-save")
+	}
 	pr, pw := io.Pipe()
 	c.cmd.Stdin = pr
 	c.inPipe = pw
@@ -103,6 +136,17 @@ func Connection(opts ...ConnOption) (*Conn, error) {
 		c.cmd.Stdout = os.Stdout
 		c.cmd.Stderr = os.Stderr
 	}
+	if c.output != nil {
+		stdout := &lineWriter{o: c.output, kind: OutputConsole}
+		stderr := &lineWriter{o: c.output, kind: OutputMessage}
+		if cfg.debug {
+			c.cmd.Stdout = io.MultiWriter(os.Stdout, stdout)
+			c.cmd.Stderr = io.MultiWriter(os.Stderr, stderr)
+		} else {
+			c.cmd.Stdout = stdout
+			c.cmd.Stderr = stderr
+		}
+	}
 	err = c.start()
 	if err != nil {
 		return nil, err
@@ -112,6 +156,13 @@ func Connection(opts ...ConnOption) (*Conn, error) {
 		err = errors.Wrap(err, "failed to start server")
 		goto ErrCleanup
 	}
+	if cfg.transcript != "" {
+		c.transcript, err = newScriptWriter(cfg.transcript, TranscriptFile)
+		if err != nil {
+			err = errors.Wrap(err, "failed to start transcript")
+			goto ErrCleanup
+		}
+	}
 	err = c.directR("library(jsonlite)\n")
 	if err != nil {
 		err = errors.Wrap(err, "failed to load jsonlite library")
@@ -122,6 +173,19 @@ func Connection(opts ...ConnOption) (*Conn, error) {
 		err = errors.Wrap(err, "failed to load RCurl library")
 		goto ErrCleanup
 	}
+	if c.installer != nil {
+		err = c.installer.setup(&c)
+		if err != nil {
+			err = errors.Wrap(err, "failed to set up package library")
+			goto ErrCleanup
+		}
+	}
+	if lock != nil {
+		err = c.VerifyLockfile(lock)
+		if err != nil {
+			goto ErrCleanup
+		}
+	}
 	runtime.SetFinalizer(&c, func(c *Conn) { c.Close() })
 	return &c, nil
 
@@ -139,6 +203,11 @@ func (c *Conn) Close() error {
 	<-c.closed
 	err1 := c.waitErr
 	err2 := c.server.s.Stop()
+	if c.transcript != nil {
+		if err := c.transcript.close(); err2 == nil {
+			err2 = err
+		}
+	}
 	if err1 != nil {
 		return err1
 	}
@@ -153,6 +222,7 @@ func (c *Conn) directR(cmd string) error {
 }
 
 const cmdStr = `..rgo.ret = c("", "")
+..rgo.time = proc.time()
 tryCatch({
 	%s
 }, warning = function(w) {
@@ -160,8 +230,7 @@ tryCatch({
 }, error = function(e) {
 	..rgo.ret[2] <<- conditionMessage(e)
 })
-print(..rgo.ret)
-httpPUT("http://localhost:%d/%s", toJSON(..rgo.ret))
+%shttpPUT("http://localhost:%d/%s", toJSON(c(..rgo.ret, unname((proc.time() - ..rgo.time)[1:2]))))
 `
 
 type res struct {
@@ -170,6 +239,12 @@ type res struct {
 	strict  bool
 }
 
+// newRes decodes the result sent by cmdStr, which holds the warning
+// message followed by the error message.
+func newRes(pair []string, strict bool) res {
+	return res{Warning: pair[0], Error: pair[1], strict: strict}
+}
+
 type rError string
 
 func (e rError) Error() string { return string(e) }
@@ -203,41 +278,94 @@ func (c *Conn) Strict() error {
 // R sends a command to R. An Error or Warning generated by the
 // command will be returned as an RError or RWarning.
 func (c *Conn) R(cmd string) error {
+	return c.exec(cmd, cmd)
+}
+
+// exec runs cmd. If the Conn has a transcript, record is written to
+// it in place of cmd. Commands that cannot be reproduced outside of
+// this Conn should pass a different record, or an empty one to skip
+// recording.
+func (c *Conn) exec(cmd, record string) error {
 	if c.err != nil {
 		return c.err
 	}
+	for _, h := range c.hooks {
+		h.OnCommandStart(cmd)
+	}
+	start := time.Now()
+	stats, err := c.r(cmd)
+	stats.Cmd = cmd
+	stats.Duration = time.Since(start)
+	stats.BytesSent = c.bytesSent
+	stats.BytesReceived = atomic.SwapInt64(&c.bytesRecv, 0)
+	c.bytesSent = 0
+	stats.Err = err
+	switch {
+	case err == nil:
+		stats.Result = ResultOK
+	case IsWarning(err):
+		stats.Result = ResultWarning
+	default:
+		stats.Result = ResultError
+	}
+	for _, h := range c.hooks {
+		h.OnCommandEnd(stats)
+	}
+	if c.transcript != nil && record != "" {
+		if terr := c.transcript.command(record, err); terr != nil && c.err == nil {
+			c.err = errors.Wrap(terr, "failed to record command")
+			return c.err
+		}
+	}
+	return err
+}
+
+// r runs cmd and reports the CPU time that R used to run it.
+func (c *Conn) r(cmd string) (CommandStats, error) {
+	var stats CommandStats
 	key := "r.result"
 	rch := make(chan readerDone)
 	c.server.putFwd(key, rch)
 	defer c.server.rmFwd(key)
-	fmt.Fprintf(c.inPipe, cmdStr, cmd, c.server.port, key)
+	extraStr := ""
+	if c.debug {
+		extraStr = "print(..rgo.ret)\n"
+	}
+	if c.output != nil {
+		extraStr += outputSyncStr
+	}
+	c.output.setCmd(cmd)
+	fmt.Fprintf(c.inPipe, cmdStr, cmd, extraStr, c.server.port, key)
 	var rd readerDone
 	select {
 	case <-c.closed:
 		c.err = c.waitErr
-		return c.err
+		return stats, c.err
 	case thisRD := <-rch:
 		rd = thisRD
 	}
 	defer close(rd.done)
+	c.output.wait(c.closed)
 	dec := json.NewDecoder(rd.r)
 	var resultPair []string
 	if err := dec.Decode(&resultPair); err != nil {
 		c.err = errors.Wrap(err, "error while decoding result")
-		return c.err
+		return stats, c.err
 	}
-	if len(resultPair) != 2 {
-		c.err = errors.Errorf("invalid result pair: %v has length %d", resultPair, len(resultPair))
-		return c.err
+	if len(resultPair) != 4 {
+		c.err = errors.Errorf("invalid result: %v has length %d", resultPair, len(resultPair))
+		return stats, c.err
 	}
-	result := res{resultPair[0], resultPair[1], c.strict}
-	c.err = result.toError()
+	stats.UserTime = parseSeconds(resultPair[2])
+	stats.SystemTime = parseSeconds(resultPair[3])
+	c.err = newRes(resultPair, c.strict).toError()
 	if IsWarning(c.err) {
 		err := c.err
 		c.err = nil
-		return err
+		c.output.emit(OutputWarning, err.Error())
+		return stats, err
 	}
-	return c.err
+	return stats, c.err
 }
 
 // Rf is like R but takes a format string and arguments.
@@ -251,14 +379,15 @@ func (c *Conn) getuid() uint64 {
 	return x
 }
 
-func (c *Conn) write(data interface{}) (string, error) {
+func (c *Conn) write(data interface{}) (string, []byte, error) {
 	b, err := json.Marshal(data)
 	if err != nil {
-		return "", errors.Wrap(err, "unable to serialize data")
+		return "", nil, errors.Wrap(err, "unable to serialize data")
 	}
 	key := fmt.Sprintf("go.data.%d", c.getuid())
 	c.server.putData(key, b)
-	return key, nil
+	c.bytesSent += int64(len(b))
+	return key, b, nil
 }
 
 // Send sends data into R. data must be json-serializable.
@@ -266,7 +395,7 @@ func (c *Conn) Send(data interface{}, name string) error {
 	if c.err != nil {
 		return c.err
 	}
-	key, err := c.write(data)
+	key, b, err := c.write(data)
 	if key != "" {
 		defer c.server.rmData(key)
 	}
@@ -274,13 +403,26 @@ func (c *Conn) Send(data interface{}, name string) error {
 		c.err = err
 		return err
 	}
-	err = c.Rf("%s = fromJSON(getURL(\"http://localhost:%d/%s\"))", name, c.server.port, key)
+	var record string
+	if c.transcript != nil {
+		record, err = c.transcript.data(b, name)
+		if err != nil {
+			c.err = errors.Wrap(err, "failed to record data")
+			return c.err
+		}
+	}
+	cmd := fmt.Sprintf("%s = fromJSON(getURL(\"http://localhost:%d/%s\"))", name, c.server.port, key)
+	err = c.exec(cmd, record)
 	return errors.Wrap(err, "failed to deserialize data in R")
 }
 
 // SendDF sends a DataFrame and properly unpacks it as an
 // R data frame.
 func (c *Conn) SendDF(df dataframe.DataFrame, name string) error {
+	return sendDF(c, df, name)
+}
+
+func sendDF(c Executor, df dataframe.DataFrame, name string) error {
 	colNames := df.ColNames()
 	colVars := make([]string, len(colNames))
 	for i := range colNames {
@@ -321,6 +463,11 @@ func (c *Conn) SendDF(df dataframe.DataFrame, name string) error {
 	return errors.Wrap(c.Rf("%s <- ..rgo.df.result", name), "failed to assign dataframe to variable")
 }
 
+// getStr sends a variable to Go. Numbers are written with 17
+// significant digits, which is enough for every double to be read
+// back exactly, rather than jsonlite's default of 4 decimal digits.
+const getStr = `httpPUT("http://localhost:%d/%s", toJSON(%s, digits=I(17)))`
+
 // Get gets data from R. data will be deserialized from json.
 func (c *Conn) Get(data interface{}, name string) error {
 	if c.err != nil {
@@ -333,12 +480,13 @@ func (c *Conn) Get(data interface{}, name string) error {
 
 	errCh := make(chan error)
 	go func() {
-		e := c.Rf("httpPUT(\"http://localhost:%d/%s\", toJSON(%s))", c.server.port, key, name)
+		cmd := fmt.Sprintf(getStr, c.server.port, key, name)
+		e := c.exec(cmd, "")
 		errCh <- errors.Wrap(e, "failed to transfer data into http server")
 	}()
 
 	rd := <-rch
-	dec := json.NewDecoder(rd.r)
+	dec := json.NewDecoder(&countingReader{rd.r, &c.bytesRecv})
 	err := errors.Wrap(dec.Decode(data), "error decoding data from R")
 	close(rd.done)
 	c.err = <-errCh
@@ -382,6 +530,12 @@ func (e depError) MissingDependencies() []string {
 	return e.deps
 }
 
+// IsDependencyError returns whether e is a DependencyError.
+func IsDependencyError(e error) bool {
+	_, ok := e.(DependencyError)
+	return ok
+}
+
 func IsError(e error) bool {
 	_, ok := e.(RError)
 	return ok
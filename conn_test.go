@@ -1,25 +1,34 @@
 package rgo
 
 import (
+	"os/exec"
+	"reflect"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/uluyol/rgo/dataframe"
 )
 
-func newTestConn(t *testing.T) *Conn {
-	var c *Conn
-	var err error
+// newTestConn creates a Conn for the test. The test is skipped if R
+// or its dependencies are not installed. The rgotest package cannot
+// be used here as it imports this package.
+func newTestConn(t *testing.T, opts ...ConnOption) *Conn {
+	if _, err := exec.LookPath("R"); err != nil {
+		t.Skip("R is not installed")
+	}
 	if testing.Verbose() {
-		c, err = Connection(WithDebug())
-	} else {
-		c, err = Connection()
+		opts = append(opts, WithDebug())
+	}
+	c, err := Connection(opts...)
+	if IsDependencyError(err) {
+		t.Skipf("missing R dependencies: %v", err)
 	}
 	if err != nil {
-		t.Errorf("failed to create connection: %v", err)
+		t.Fatalf("failed to create connection: %v", err)
 	}
 	if c == nil {
-		t.Error("got nil connection")
+		t.Fatal("got nil connection")
 	}
 	return c
 }
@@ -60,6 +69,30 @@ func TestConn(t *testing.T) {
 	c.Close()
 }
 
+func TestGetPrecision(t *testing.T) {
+	c := newTestConn(t)
+	defer c.Close()
+
+	want := []float64{1.0000001, 123456.789, 1e-9, 0.1, 1.0 / 3}
+	if err := c.Send(want, "x"); err != nil {
+		t.Fatalf("unexpected error sending data: %v", err)
+	}
+	var got []float64
+	if err := c.Get(&got, "as.double(x)"); err != nil {
+		t.Fatalf("couldn't get 'x': %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	var third []float64
+	if err := c.Get(&third, "1/3"); err != nil {
+		t.Fatalf("couldn't get 1/3: %v", err)
+	}
+	if len(third) != 1 || third[0] != 1.0/3 {
+		t.Errorf("expected %v, got %v", 1.0/3, third)
+	}
+}
+
 func TestConnStrict(t *testing.T) {
 	c := newTestConn(t)
 	defer c.Close()
@@ -154,6 +187,63 @@ func TestConnInvalid(t *testing.T) {
 	}
 }
 
+func TestRegisterName(t *testing.T) {
+	c := &Conn{}
+	for _, name := range []string{"my cb", "", "1cb", "cb(x)", ".1", "function", "NA_real_", "..1"} {
+		if err := c.Register(name, nil); err == nil {
+			t.Errorf("expected error registering callback %q", name)
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	c := newTestConn(t)
+	defer c.Close()
+
+	err := c.Register("gosquare", func(args []float64) ([]float64, error) {
+		res := make([]float64, len(args))
+		for i, v := range args {
+			res[i] = v * v
+		}
+		return res, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error registering callback: %v", err)
+	}
+	if err := c.R("res <- optimize(function(x) gosquare(x - 3), c(0, 10))$minimum"); err != nil {
+		t.Fatalf("unexpected error calling callback: %v", err)
+	}
+	var res []float64
+	if err := c.Get(&res, "res"); err != nil {
+		t.Fatalf("couldn't get 'res': %v", err)
+	}
+	if len(res) != 1 || res[0] < 2.99 || res[0] > 3.01 {
+		t.Errorf("expected minimum near 3, got %v", res)
+	}
+}
+
+func TestResultKinds(t *testing.T) {
+	if err := newRes([]string{"w", "", "0", "0"}, false).toError(); !IsWarning(err) || err.Error() != "w" {
+		t.Errorf("expected warning %q, got %#v", "w", err)
+	}
+	if err := newRes([]string{"", "e", "0", "0"}, false).toError(); !IsError(err) || err.Error() != "e" {
+		t.Errorf("expected error %q, got %#v", "e", err)
+	}
+
+	c := newTestConn(t)
+	defer c.Close()
+	err := c.R("warning('w')")
+	if !IsWarning(err) {
+		t.Errorf("expected warning, got %#v", err)
+	}
+	if err := c.R("x <- 1"); err != nil {
+		t.Errorf("warnings should not be sticky, got %v", err)
+	}
+	if err := c.R("stop('e')"); !IsError(err) {
+		t.Errorf("expected error, got %#v", err)
+	}
+}
+
 func TestErrors(t *testing.T) {
 	var err error
 	err = rError("")
@@ -172,3 +262,73 @@ func TestErrors(t *testing.T) {
 		t.Error("depError does not implement DependencyError")
 	}
 }
+
+func TestOutput(t *testing.T) {
+	var mu sync.Mutex
+	var got []Output
+	c := newTestConn(t, WithOutput(func(o Output) {
+		mu.Lock()
+		got = append(got, o)
+		mu.Unlock()
+	}))
+	defer c.Close()
+
+	cmds := []string{`cat("hello\n")`, `message("progress")`, `warning("careful")`}
+	for _, cmd := range cmds {
+		c.R(cmd)
+	}
+	want := []Output{
+		{OutputConsole, cmds[0], "hello"},
+		{OutputMessage, cmds[1], "progress"},
+		{OutputWarning, cmds[2], "careful"},
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("line %d: expected %v, got %v", i, want[i], got[i])
+		}
+	}
+}
+
+type recordingHook struct {
+	started []string
+	ended   []CommandStats
+}
+
+func (h *recordingHook) OnCommandStart(cmd string)   { h.started = append(h.started, cmd) }
+func (h *recordingHook) OnCommandEnd(s CommandStats) { h.ended = append(h.ended, s) }
+
+func TestHooks(t *testing.T) {
+	var h recordingHook
+	c := newTestConn(t, WithHook(&h))
+	defer c.Close()
+
+	c.Send([]float64{1, 2, 3}, "x")
+	c.R("warning('w')")
+	var x []float64
+	c.Get(&x, "x")
+	c.R("stop('e')")
+
+	if len(h.started) != 4 || len(h.ended) != 4 {
+		t.Fatalf("expected 4 commands, got %d started and %d ended", len(h.started), len(h.ended))
+	}
+	if h.ended[0].BytesSent == 0 {
+		t.Errorf("expected Send to report bytes sent")
+	}
+	if h.ended[2].BytesReceived == 0 {
+		t.Errorf("expected Get to report bytes received")
+	}
+	results := []Result{ResultOK, ResultWarning, ResultOK, ResultError}
+	for i, s := range h.ended {
+		if s.Cmd != h.started[i] {
+			t.Errorf("command %d: started %q but ended %q", i, h.started[i], s.Cmd)
+		}
+		if s.Result != results[i] {
+			t.Errorf("command %d: expected result %v, got %v", i, results[i], s.Result)
+		}
+	}
+}
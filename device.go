@@ -0,0 +1,143 @@
+package rgo
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// DeviceSpec describes a graphics device. It is implemented by PNG,
+// SVG and PDF.
+type DeviceSpec interface {
+	// openCmd returns the command that opens the device so that
+	// it renders into path.
+	openCmd(path string) string
+}
+
+// PNG is a DeviceSpec for PNG images. Zero values use R's defaults,
+// which are 480x480 pixels at 72 DPI with 12 point text.
+type PNG struct {
+	Width     int // in pixels
+	Height    int // in pixels
+	DPI       int
+	PointSize float64
+}
+
+// SVG is a DeviceSpec for SVG images. Zero values use R's default
+// size of 7x7 inches with 12 point text.
+type SVG struct {
+	Width     float64 // in inches
+	Height    float64 // in inches
+	PointSize float64
+}
+
+// PDF is a DeviceSpec for PDF documents. Every new plot starts a
+// new page. Zero values use R's default size of 7x7 inches with 12
+// point text.
+type PDF struct {
+	Width     float64 // in inches
+	Height    float64 // in inches
+	PointSize float64
+}
+
+func orDefault(v, def float64) string {
+	if v == 0 {
+		v = def
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// pointSize returns the pointsize argument for size, if it is set.
+func pointSize(size float64) string {
+	if size <= 0 {
+		return ""
+	}
+	return ", pointsize=" + strconv.FormatFloat(size, 'g', -1, 64)
+}
+
+func (d PNG) openCmd(path string) string {
+	res := "NA"
+	if d.DPI > 0 {
+		res = strconv.Itoa(d.DPI)
+	}
+	return fmt.Sprintf(`png(%q, width=%s, height=%s, res=%s%s, type=if (capabilities("cairo")) "cairo" else getOption("bitmapType"))`,
+		path, orDefault(float64(d.Width), 480), orDefault(float64(d.Height), 480), res, pointSize(d.PointSize))
+}
+
+func (d SVG) openCmd(path string) string {
+	return fmt.Sprintf("svg(%q, width=%s, height=%s%s)", path, orDefault(d.Width, 7), orDefault(d.Height, 7), pointSize(d.PointSize))
+}
+
+func (d PDF) openCmd(path string) string {
+	return fmt.Sprintf("pdf(%q, width=%s, height=%s%s)", path, orDefault(d.Width, 7), orDefault(d.Height, 7), pointSize(d.PointSize))
+}
+
+// Device is a graphics device opened by Conn.Device. It renders into
+// a temporary file that is removed when the device is closed.
+type Device struct {
+	c      *Conn
+	path   string
+	rvar   string
+	closed bool
+}
+
+// Device opens a graphics device and makes it the current device, so
+// that plots are drawn on it instead of a file in R's working
+// directory. Call Close to retrieve what was drawn.
+func (c *Conn) Device(spec DeviceSpec) (*Device, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	f, err := ioutil.TempFile("", "rgo-device")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create device file")
+	}
+	f.Close()
+	d := &Device{c: c, path: f.Name(), rvar: fmt.Sprintf("..rgo.dev.%d", c.getuid())}
+	if err := c.R(spec.openCmd(d.path)); err != nil {
+		os.Remove(d.path)
+		return nil, errors.Wrap(err, "failed to open device")
+	}
+	if err := c.Rf("%s <- dev.cur()", d.rvar); err != nil {
+		os.Remove(d.path)
+		return nil, errors.Wrap(err, "failed to find device")
+	}
+	return d, nil
+}
+
+// Activate makes d the current device again, e.g. after another
+// device was opened.
+func (d *Device) Activate() error {
+	if d.closed {
+		return errors.New("device already closed")
+	}
+	return errors.Wrap(d.c.Rf("invisible(dev.set(%s))", d.rvar), "failed to activate device")
+}
+
+// Close closes the device and returns what was drawn on it.
+func (d *Device) Close() ([]byte, error) {
+	if d.closed {
+		return nil, errors.New("device already closed")
+	}
+	d.closed = true
+	defer os.Remove(d.path)
+	if err := d.c.Rf("invisible(dev.off(%s))", d.rvar); err != nil {
+		return nil, errors.Wrap(err, "failed to close device")
+	}
+	b, err := ioutil.ReadFile(d.path)
+	return b, errors.Wrap(err, "unable to read rendered output")
+}
+
+// CloseTo is like Close but writes what was drawn to w.
+func (d *Device) CloseTo(w io.Writer) error {
+	b, err := d.Close()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
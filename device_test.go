@@ -0,0 +1,60 @@
+package rgo
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDeviceOpenCmd(t *testing.T) {
+	testCases := []struct {
+		Spec DeviceSpec
+		Want string
+	}{
+		{PNG{}, `png("f", width=480, height=480, res=NA, type=if (capabilities("cairo")) "cairo" else getOption("bitmapType"))`},
+		{PNG{Width: 800, Height: 600, DPI: 144}, `png("f", width=800, height=600, res=144, type=if (capabilities("cairo")) "cairo" else getOption("bitmapType"))`},
+		{SVG{Width: 3.5}, `svg("f", width=3.5, height=7)`},
+		{PDF{Width: 4, Height: 2.5}, `pdf("f", width=4, height=2.5)`},
+		{PDF{PointSize: 9}, `pdf("f", width=7, height=7, pointsize=9)`},
+		{PNG{DPI: 300, PointSize: 8}, `png("f", width=480, height=480, res=300, pointsize=8, type=if (capabilities("cairo")) "cairo" else getOption("bitmapType"))`},
+	}
+	for _, c := range testCases {
+		if got := c.Spec.openCmd("f"); got != c.Want {
+			t.Errorf("%#v: expected %q, got %q", c.Spec, c.Want, got)
+		}
+	}
+}
+
+func TestDevice(t *testing.T) {
+	c := newTestConn(t)
+	defer c.Close()
+
+	testCases := []struct {
+		Spec   DeviceSpec
+		Prefix []byte
+	}{
+		{PNG{Width: 200, Height: 200}, []byte("\x89PNG")},
+		{PDF{}, []byte("%PDF")},
+	}
+	for _, tc := range testCases {
+		d, err := c.Device(tc.Spec)
+		if err != nil {
+			t.Fatalf("%#v: failed to open device: %v", tc.Spec, err)
+		}
+		if err := d.Activate(); err != nil {
+			t.Errorf("%#v: failed to activate device: %v", tc.Spec, err)
+		}
+		if err := c.R("plot(1:10)"); err != nil {
+			t.Errorf("%#v: failed to plot: %v", tc.Spec, err)
+		}
+		var buf bytes.Buffer
+		if err := d.CloseTo(&buf); err != nil {
+			t.Fatalf("%#v: failed to close device: %v", tc.Spec, err)
+		}
+		if !bytes.HasPrefix(buf.Bytes(), tc.Prefix) {
+			t.Errorf("%#v: expected output starting with %q", tc.Spec, tc.Prefix)
+		}
+		if _, err := d.Close(); err == nil {
+			t.Errorf("%#v: expected error closing device twice", tc.Spec)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package rgo
+
+import "github.com/uluyol/rgo/dataframe"
+
+// Executor is the set of operations shared by the ways that rgo can
+// run R code. *Conn runs commands in an R process while *Script
+// writes them into an R script.
+type Executor interface {
+	R(cmd string) error
+	Rf(format string, args ...interface{}) error
+	Send(data interface{}, name string) error
+	SendDF(df dataframe.DataFrame, name string) error
+	Get(data interface{}, name string) error
+	Error() error
+	Close() error
+}
+
+var (
+	_ Executor = (*Conn)(nil)
+	_ Executor = (*Script)(nil)
+)
@@ -0,0 +1,88 @@
+package rgo
+
+import (
+	"io"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// Result classifies the outcome of a command.
+type Result int
+
+const (
+	ResultOK Result = iota
+	ResultWarning
+	ResultError
+)
+
+func (r Result) String() string {
+	switch r {
+	case ResultOK:
+		return "ok"
+	case ResultWarning:
+		return "warning"
+	case ResultError:
+		return "error"
+	}
+	return "Result(" + strconv.Itoa(int(r)) + ")"
+}
+
+// CommandStats describes a single command that was run in R.
+// Operations such as Send and Get run commands internally and
+// are reported like any other command.
+type CommandStats struct {
+	Cmd      string
+	Duration time.Duration
+
+	// BytesSent is the amount of data R fetched from Go
+	// for the command (e.g. by Send).
+	BytesSent int64
+	// BytesReceived is the amount of data Go received from
+	// R during the command (e.g. by Get).
+	BytesReceived int64
+
+	Result Result
+	Err    error
+
+	// UserTime and SystemTime are the CPU time used by the R
+	// process to run the command.
+	UserTime   time.Duration
+	SystemTime time.Duration
+}
+
+// Hook is notified about every command that is run by a Conn.
+// Hooks are called synchronously from the goroutine running the
+// command, but a Hook shared by multiple Conns may be called
+// concurrently.
+type Hook interface {
+	OnCommandStart(cmd string)
+	OnCommandEnd(s CommandStats)
+}
+
+// WithHook adds a Hook to the Conn. It may be specified multiple
+// times to add several Hooks.
+func WithHook(h Hook) ConnOption {
+	return func(c *connConfig) {
+		c.hooks = append(c.hooks, h)
+	}
+}
+
+func parseSeconds(s string) time.Duration {
+	secs, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(secs * float64(time.Second))
+}
+
+type countingReader struct {
+	r io.Reader
+	n *int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	atomic.AddInt64(r.n, int64(n))
+	return n, err
+}
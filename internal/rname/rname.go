@@ -0,0 +1,30 @@
+// Package rname checks whether strings are syntactic R names.
+package rname
+
+import "regexp"
+
+// syntactic matches names made of letters, digits, dots and
+// underscores that start with a letter or with a dot not followed by
+// a digit.
+var syntactic = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9._]*|\.([A-Za-z._][A-Za-z0-9._]*)?)$`)
+
+// dotDot matches ..1, ..2, etc., which refer to the arguments matched
+// by ... and are reserved.
+var dotDot = regexp.MustCompile(`^\.\.[0-9]+$`)
+
+// reserved are the reserved words of R (see ?Reserved) along with in,
+// which the parser also treats as a keyword.
+var reserved = map[string]bool{
+	"if": true, "else": true, "repeat": true, "while": true, "function": true,
+	"for": true, "next": true, "break": true, "in": true,
+	"TRUE": true, "FALSE": true, "NULL": true, "Inf": true, "NaN": true,
+	"NA": true, "NA_integer_": true, "NA_real_": true, "NA_character_": true,
+	"NA_complex_": true, "...": true,
+}
+
+// Valid reports whether name can be used unquoted as an R variable or
+// argument name. Valid names only contain ASCII letters, digits, dots
+// and underscores, so they can also be used in URLs.
+func Valid(name string) bool {
+	return syntactic.MatchString(name) && !dotDot.MatchString(name) && !reserved[name]
+}
@@ -0,0 +1,40 @@
+package rname
+
+import "testing"
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{"x", true},
+		{"my.var_2", true},
+		{".hidden", true},
+		{"._x", true},
+		{".", true},
+		{"..x", true},
+		{"", false},
+		{"1x", false},
+		{"_x", false},
+		{".1", false},
+		{"my var", false},
+		{"f(x)", false},
+		{"if", false},
+		{"in", false},
+		{"function", false},
+		{"TRUE", false},
+		{"NA", false},
+		{"NA_integer_", false},
+		{"NA_real_", false},
+		{"NA_character_", false},
+		{"NA_complex_", false},
+		{"...", false},
+		{"..1", false},
+		{"..12", false},
+	}
+	for _, test := range tests {
+		if got := Valid(test.name); got != test.valid {
+			t.Errorf("Valid(%q) = %t, want %t", test.name, got, test.valid)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package rgo
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// LockedPackage is an entry in a Lockfile. Checksum is the MD5
+// checksum of the package's installed DESCRIPTION file without the
+// Packaged and Built fields, which change whenever the package is
+// rebuilt. It is optional.
+type LockedPackage struct {
+	Name     string
+	Version  string
+	Checksum string
+}
+
+// Lockfile lists the exact versions of the R packages that a
+// program expects to be installed.
+//
+// In text form, a lockfile has one package per line consisting of
+// its name, version and optionally its checksum separated by spaces.
+// Blank lines and lines starting with '#' are ignored.
+type Lockfile struct {
+	Packages []LockedPackage
+}
+
+// ReadLockfile parses a lockfile.
+func ReadLockfile(r io.Reader) (*Lockfile, error) {
+	var l Lockfile
+	s := bufio.NewScanner(r)
+	lineNum := 0
+	for s.Scan() {
+		lineNum++
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		f := strings.Fields(line)
+		if len(f) < 2 || len(f) > 3 {
+			return nil, errors.Errorf("line %d: expected name, version and optional checksum", lineNum)
+		}
+		p := LockedPackage{Name: f[0], Version: f[1]}
+		if len(f) == 3 {
+			p.Checksum = f[2]
+		}
+		l.Packages = append(l.Packages, p)
+	}
+	if err := s.Err(); err != nil {
+		return nil, errors.Wrap(err, "unable to read lockfile")
+	}
+	return &l, nil
+}
+
+// WriteTo writes l in text form to w.
+func (l *Lockfile) WriteTo(w io.Writer) (int64, error) {
+	var total int64
+	for _, p := range l.Packages {
+		line := p.Name + " " + p.Version
+		if p.Checksum != "" {
+			line += " " + p.Checksum
+		}
+		n, err := io.WriteString(w, line+"\n")
+		total += int64(n)
+		if err != nil {
+			return total, errors.Wrap(err, "unable to write lockfile")
+		}
+	}
+	return total, nil
+}
+
+// PackageMismatch describes a package whose installed version
+// differs from a Lockfile. Installed.Version is empty if the package
+// is not installed.
+type PackageMismatch struct {
+	Locked    LockedPackage
+	Installed LockedPackage
+}
+
+func (m PackageMismatch) String() string {
+	switch {
+	case m.Installed.Version == "":
+		return fmt.Sprintf("%s (want %s, not installed)", m.Locked.Name, m.Locked.Version)
+	case !sameVersion(m.Installed.Version, m.Locked.Version):
+		return fmt.Sprintf("%s (want %s, have %s)", m.Locked.Name, m.Locked.Version, m.Installed.Version)
+	}
+	return fmt.Sprintf("%s (checksum want %s, have %s)", m.Locked.Name, m.Locked.Checksum, m.Installed.Checksum)
+}
+
+// LockfileError is returned when the installed packages do not
+// match a Lockfile.
+type LockfileError struct {
+	Mismatches []PackageMismatch
+}
+
+func (e *LockfileError) Error() string {
+	s := make([]string, len(e.Mismatches))
+	for i, m := range e.Mismatches {
+		s[i] = m.String()
+	}
+	return "installed R packages do not match lockfile: " + strings.Join(s, ", ")
+}
+
+// sameVersion reports whether a and b are the same R package
+// version. packageVersion normalizes versions like 1.95-4.8 to
+// 1.95.4.8, so versions are compared by component. Unlike
+// compareVersions, missing components are not treated as zero, so
+// 1.0 and 1.0.0 differ.
+func sameVersion(a, b string) bool {
+	split := func(s string) []string {
+		return strings.FieldsFunc(s, func(r rune) bool { return r == '.' || r == '-' })
+	}
+	as, bs := split(a), split(b)
+	if len(as) != len(bs) {
+		return false
+	}
+	for i := range as {
+		if as[i] != bs[i] && compareVersions(as[i], bs[i]) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// diffLockfile compares l against the installed packages.
+func diffLockfile(l *Lockfile, installed []LockedPackage) []PackageMismatch {
+	var ms []PackageMismatch
+	for i, p := range l.Packages {
+		have := installed[i]
+		versionOK := have.Version != "" && sameVersion(have.Version, p.Version)
+		if !versionOK || (p.Checksum != "" && have.Checksum != p.Checksum) {
+			ms = append(ms, PackageMismatch{Locked: p, Installed: have})
+		}
+	}
+	return ms
+}
+
+// WithLockfile makes Connection verify that the installed packages
+// match the lockfile at path. See VerifyLockfile.
+func WithLockfile(path string) ConnOption {
+	return func(c *connConfig) {
+		c.lockfile = path
+	}
+}
+
+func readLockfileAt(path string) (*Lockfile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to open lockfile")
+	}
+	defer f.Close()
+	return ReadLockfile(f)
+}
+
+// descChecksumStr defines a function that computes the checksum of
+// a DESCRIPTION file without its Packaged and Built fields.
+const descChecksumStr = `..rgo.lock.md5 <- function(f) {
+	d <- read.dcf(f)[1, ]
+	d <- d[!(names(d) %in% c("Packaged", "Built"))]
+	t <- tempfile()
+	on.exit(unlink(t))
+	writeLines(paste0(names(d), ": ", d), t, useBytes=TRUE)
+	unname(tools::md5sum(t))
+}`
+
+const packageInfoStr = `..rgo.lock.info <- local({
+	info <- function(p) {
+		v <- tryCatch(as.character(packageVersion(p)), error=function(e) "")
+		f <- system.file("DESCRIPTION", package=p)
+		c(v, if (f == "") "" else ..rgo.lock.md5(f))
+	}
+	m <- vapply(..rgo.lock.names, info, c("", ""), USE.NAMES=FALSE)
+	list(version=m[1, ], checksum=m[2, ])
+})`
+
+// packageInfo returns the installed version and checksum of each
+// package. Missing packages have an empty version and checksum.
+func (c *Conn) packageInfo(names []string) ([]LockedPackage, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	if err := c.Send(names, "..rgo.lock.names"); err != nil {
+		return nil, err
+	}
+	if err := c.R(descChecksumStr); err != nil {
+		return nil, err
+	}
+	if err := c.R(packageInfoStr); err != nil {
+		return nil, err
+	}
+	var info struct {
+		Version  []string `json:"version"`
+		Checksum []string `json:"checksum"`
+	}
+	if err := c.Get(&info, "..rgo.lock.info"); err != nil {
+		return nil, err
+	}
+	if len(info.Version) != len(names) || len(info.Checksum) != len(names) {
+		return nil, errors.Errorf("got info for %d packages, expected %d", len(info.Version), len(names))
+	}
+	pkgs := make([]LockedPackage, len(names))
+	for i := range names {
+		pkgs[i] = LockedPackage{names[i], info.Version[i], info.Checksum[i]}
+	}
+	return pkgs, nil
+}
+
+// VerifyLockfile checks that the installed packages match l. If
+// they do not, a *LockfileError describing the differences is
+// returned. Checksums are only compared if l has them.
+func (c *Conn) VerifyLockfile(l *Lockfile) error {
+	if c.err != nil {
+		return c.err
+	}
+	names := make([]string, len(l.Packages))
+	for i, p := range l.Packages {
+		names[i] = p.Name
+	}
+	installed, err := c.packageInfo(names)
+	if err != nil {
+		return errors.Wrap(err, "failed to check installed packages")
+	}
+	if ms := diffLockfile(l, installed); len(ms) > 0 {
+		return &LockfileError{ms}
+	}
+	return nil
+}
+
+const sessionPackagesStr = `..rgo.lock.names <- local({
+	s <- sessionInfo()
+	p <- c(names(s$otherPkgs), names(s$loadedOnly))
+	sort(p[!vapply(p, function(x) identical(packageDescription(x)$Priority, "base"), TRUE)])
+})`
+
+// Lockfile creates a Lockfile for the given packages. If none are
+// given, all packages loaded in the session except for base
+// packages are included.
+func (c *Conn) Lockfile(pkgs ...string) (*Lockfile, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	if len(pkgs) == 0 {
+		if err := c.R(sessionPackagesStr); err != nil {
+			return nil, errors.Wrap(err, "failed to list loaded packages")
+		}
+		if err := c.Get(&pkgs, "..rgo.lock.names"); err != nil {
+			return nil, errors.Wrap(err, "failed to get loaded packages")
+		}
+	}
+	installed, err := c.packageInfo(pkgs)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to check installed packages")
+	}
+	for _, p := range installed {
+		if p.Version == "" {
+			return nil, depError{[]string{p.Name}}
+		}
+	}
+	return &Lockfile{installed}, nil
+}
@@ -0,0 +1,140 @@
+package rgo
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLockfileReadWrite(t *testing.T) {
+	in := `# comment
+jsonlite 1.5 0f0e8ac8d7a3d9bbd2a93e1e0b9b7cce
+
+RCurl   1.95-4.8
+`
+	l, err := ReadLockfile(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("failed to read lockfile: %v", err)
+	}
+	want := []LockedPackage{
+		{"jsonlite", "1.5", "0f0e8ac8d7a3d9bbd2a93e1e0b9b7cce"},
+		{"RCurl", "1.95-4.8", ""},
+	}
+	if !reflect.DeepEqual(l.Packages, want) {
+		t.Errorf("expected %v, got %v", want, l.Packages)
+	}
+	var buf bytes.Buffer
+	if _, err := l.WriteTo(&buf); err != nil {
+		t.Fatalf("failed to write lockfile: %v", err)
+	}
+	wantOut := "jsonlite 1.5 0f0e8ac8d7a3d9bbd2a93e1e0b9b7cce\nRCurl 1.95-4.8\n"
+	if buf.String() != wantOut {
+		t.Errorf("expected %q, got %q", wantOut, buf.String())
+	}
+
+	if _, err := ReadLockfile(strings.NewReader("jsonlite\n")); err == nil {
+		t.Errorf("expected error for entry without version")
+	}
+}
+
+func TestDiffLockfile(t *testing.T) {
+	l := &Lockfile{[]LockedPackage{
+		{"a", "1.0", ""},
+		{"b", "1.0", "abc"},
+		{"c", "2.0", ""},
+		{"d", "1.0", "abc"},
+		{"RCurl", "1.95-4.8", ""},
+		{"e", "1.0", ""},
+	}}
+	installed := []LockedPackage{
+		{"a", "1.0", "xyz"},
+		{"b", "1.0", "def"},
+		{"c", "", ""},
+		{"d", "1.1", "abc"},
+		{"RCurl", "1.95.4.8", ""},
+		{"e", "1.0.0", ""},
+	}
+	ms := diffLockfile(l, installed)
+	if len(ms) != 4 {
+		t.Fatalf("expected 4 mismatches, got %v", ms)
+	}
+	err := (&LockfileError{ms}).Error()
+	want := "installed R packages do not match lockfile: b (checksum want abc, have def), c (want 2.0, not installed), d (want 1.0, have 1.1), e (want 1.0, have 1.0.0)"
+	if err != want {
+		t.Errorf("expected %q, got %q", want, err)
+	}
+}
+
+func TestLockfile(t *testing.T) {
+	c := newTestConn(t)
+	defer c.Close()
+
+	l, err := c.Lockfile()
+	if err != nil {
+		t.Fatalf("failed to create lockfile: %v", err)
+	}
+	found := false
+	for _, p := range l.Packages {
+		if p.Name == "jsonlite" && p.Version != "" && p.Checksum != "" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected jsonlite to be locked, got %v", l.Packages)
+	}
+	if err := c.VerifyLockfile(l); err != nil {
+		t.Errorf("unexpected error verifying lockfile: %v", err)
+	}
+	l.Packages = append(l.Packages, LockedPackage{"rgo.no.such.package", "1.0", ""})
+	err = c.VerifyLockfile(l)
+	if lerr, ok := err.(*LockfileError); !ok || len(lerr.Mismatches) != 1 {
+		t.Errorf("expected a single mismatch, got %v", err)
+	}
+}
+
+func TestDescChecksum(t *testing.T) {
+	c := newTestConn(t)
+	defer c.Close()
+
+	dir, err := ioutil.TempDir("", "rgo-lockfile")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	descs := []string{
+		"Package: a\nVersion: 1.0\nPackaged: 2016-01-01 10:00:00 UTC\nBuilt: R 3.2.3; ; 2016-01-02 10:00:00 UTC; unix\n",
+		"Package: a\nVersion: 1.0\nPackaged: 2016-04-30 09:00:00 UTC\nBuilt: R 3.3.0; ; 2016-05-01 12:00:00 UTC; unix\n",
+		"Package: a\nVersion: 1.1\nPackaged: 2016-01-01 10:00:00 UTC\nBuilt: R 3.2.3; ; 2016-01-02 10:00:00 UTC; unix\n",
+	}
+	paths := make([]string, len(descs))
+	for i, d := range descs {
+		paths[i] = filepath.Join(dir, fmt.Sprintf("DESCRIPTION%d", i))
+		if err := ioutil.WriteFile(paths[i], []byte(d), 0644); err != nil {
+			t.Fatalf("unable to write DESCRIPTION: %v", err)
+		}
+	}
+	if err := c.R(descChecksumStr); err != nil {
+		t.Fatalf("failed to define checksum function: %v", err)
+	}
+	if err := c.Send(paths, "paths"); err != nil {
+		t.Fatalf("failed to send paths: %v", err)
+	}
+	var sums []string
+	if err := c.Get(&sums, "vapply(paths, ..rgo.lock.md5, \"\", USE.NAMES=FALSE)"); err != nil {
+		t.Fatalf("failed to get checksums: %v", err)
+	}
+	if len(sums) != 3 {
+		t.Fatalf("expected 3 checksums, got %v", sums)
+	}
+	if sums[0] != sums[1] {
+		t.Errorf("checksum changed with the Built field: %s != %s", sums[0], sums[1])
+	}
+	if sums[0] == sums[2] {
+		t.Errorf("checksum did not change with the version: %s", sums[0])
+	}
+}
@@ -0,0 +1,156 @@
+package rgo
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// OutputKind describes where a line of R output came from.
+type OutputKind int
+
+const (
+	// OutputConsole is printed output, e.g. from print() or cat().
+	OutputConsole OutputKind = iota
+	// OutputMessage is diagnostic output, e.g. from message().
+	OutputMessage
+	// OutputWarning is a warning returned by a command.
+	OutputWarning
+)
+
+func (k OutputKind) String() string {
+	switch k {
+	case OutputConsole:
+		return "console"
+	case OutputMessage:
+		return "message"
+	case OutputWarning:
+		return "warning"
+	}
+	return fmt.Sprintf("OutputKind(%d)", int(k))
+}
+
+// Output is a single line of output produced by R.
+type Output struct {
+	Kind OutputKind
+	// Cmd is the command that was running when the line
+	// was produced.
+	Cmd  string
+	Text string
+}
+
+// OutputHandler receives output from R as it is produced. Console
+// and message lines are passed from two separate goroutines, while
+// warnings are passed from the goroutine running the command, so an
+// OutputHandler may be called concurrently and must be safe for
+// concurrent use. Console and message lines are each passed in order,
+// but their order relative to each other is not preserved.
+type OutputHandler func(Output)
+
+// WithOutput passes every line that R prints to h while commands
+// are still executing. R will not echo the commands it runs.
+func WithOutput(h OutputHandler) ConnOption {
+	return func(c *connConfig) {
+		c.output = h
+	}
+}
+
+// WithOutputWriter is like WithOutput but writes each line to w.
+// Warnings are prefixed with "Warning: ".
+func WithOutputWriter(w io.Writer) ConnOption {
+	var mu sync.Mutex
+	return WithOutput(func(o Output) {
+		defer mu.Unlock()
+		mu.Lock()
+		if o.Kind == OutputWarning {
+			fmt.Fprintf(w, "Warning: %s\n", o.Text)
+		} else {
+			fmt.Fprintln(w, o.Text)
+		}
+	})
+}
+
+// outputState tracks the command that is running so that lines
+// of output can be attributed to it. It is kept separate from Conn
+// so that the goroutines copying R's output do not keep the Conn
+// from being finalized.
+type outputState struct {
+	h      OutputHandler
+	synced chan struct{}
+	mu     sync.Mutex
+	cmd    string
+}
+
+func newOutputState(h OutputHandler) *outputState {
+	return &outputState{h: h, synced: make(chan struct{}, 2)}
+}
+
+// outputSyncStr makes R write syncMarker to both stdout and stderr
+// on a line of its own. It is run after every command so that Conn can
+// wait until all output of the command has been handled.
+const (
+	syncMarker    = "\x01rgo.sync"
+	outputSyncStr = "cat(\"\\n\\001rgo.sync\\n\"); message(\"\\n\\001rgo.sync\")\n"
+)
+
+// wait blocks until the output written before outputSyncStr was run
+// has been handled or closed is closed.
+func (o *outputState) wait(closed <-chan struct{}) {
+	if o == nil {
+		return
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case <-o.synced:
+		case <-closed:
+			return
+		}
+	}
+}
+
+func (o *outputState) setCmd(cmd string) {
+	if o == nil {
+		return
+	}
+	defer o.mu.Unlock()
+	o.mu.Lock()
+	o.cmd = cmd
+}
+
+func (o *outputState) emit(kind OutputKind, text string) {
+	if o == nil {
+		return
+	}
+	o.mu.Lock()
+	cmd := o.cmd
+	o.mu.Unlock()
+	o.h(Output{Kind: kind, Cmd: cmd, Text: text})
+}
+
+// lineWriter splits the output of R into lines and passes them to
+// an OutputHandler. Carriage returns are treated as line breaks so
+// that progress bars are reported as they are redrawn. Empty lines
+// are dropped.
+type lineWriter struct {
+	o    *outputState
+	kind OutputKind
+	buf  []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexAny(w.buf, "\r\n")
+		if i < 0 {
+			break
+		}
+		if line := string(w.buf[:i]); line == syncMarker {
+			w.o.synced <- struct{}{}
+		} else if line != "" {
+			w.o.emit(w.kind, line)
+		}
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
@@ -0,0 +1,269 @@
+package rgo
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Installer installs missing R packages without access to CRAN. It
+// is used by Require when configured using WithInstaller.
+type Installer struct {
+	// Lib is the library that packages are installed into. It is
+	// created if needed and put at the front of .libPaths(). If it
+	// is empty, the first entry of .libPaths() is used.
+	Lib string
+	// SourceDir is a directory of package source tarballs named
+	// like R CMD build names them, e.g. jsonlite_1.5.tar.gz. When
+	// several versions are present, the latest is installed.
+	SourceDir string
+	// Repo is the path of a local CRAN-like repository. It is used
+	// for packages that are not found in SourceDir.
+	Repo string
+}
+
+// WithInstaller makes Require install missing packages using inst.
+func WithInstaller(inst Installer) ConnOption {
+	return func(c *connConfig) {
+		c.installer = &inst
+	}
+}
+
+const setLibStr = `dir.create(%q, recursive=TRUE, showWarnings=FALSE)
+.libPaths(c(%q, .libPaths()))`
+
+func (inst *Installer) setup(c *Conn) error {
+	if inst.Lib == "" {
+		return nil
+	}
+	lib, err := filepath.Abs(inst.Lib)
+	if err != nil {
+		return errors.Wrap(err, "unable to find library path")
+	}
+	return c.Rf(setLibStr, lib, lib)
+}
+
+func (inst *Installer) libArg() (string, error) {
+	if inst.Lib == "" {
+		return ".libPaths()[1]", nil
+	}
+	lib, err := filepath.Abs(inst.Lib)
+	return strconv.Quote(lib), errors.Wrap(err, "unable to find library path")
+}
+
+// Installation failures are detected by checking what was installed
+// afterwards, so warnings are suppressed to keep them from stopping
+// the installation of the remaining packages.
+const (
+	installFilesStr = `suppressWarnings(install.packages(%s, lib=%s, repos=NULL, type="source", quiet=TRUE))`
+	installRepoStr  = `suppressWarnings(install.packages(%s, lib=%s, repos=paste0("file://", normalizePath(%q)), type="source", quiet=TRUE))`
+)
+
+func (inst *Installer) install(c *Conn, names []string) error {
+	lib, err := inst.libArg()
+	if err != nil {
+		return err
+	}
+	var files, rest []string
+	for _, name := range names {
+		if f := findTarball(inst.SourceDir, name); f != "" {
+			files = append(files, f)
+		} else {
+			rest = append(rest, name)
+		}
+	}
+	if len(files) > 0 {
+		if err := c.Rf(installFilesStr, rVector(files), lib); err != nil {
+			return errors.Wrap(err, "failed to install packages from source")
+		}
+	}
+	if len(rest) > 0 && inst.Repo != "" {
+		if err := c.Rf(installRepoStr, rVector(rest), lib, inst.Repo); err != nil {
+			return errors.Wrap(err, "failed to install packages from repository")
+		}
+	}
+	return nil
+}
+
+// findTarball returns the path of the latest source tarball of name
+// in dir, or "" if there is none.
+func findTarball(dir, name string) string {
+	if dir == "" {
+		return ""
+	}
+	matches, _ := filepath.Glob(filepath.Join(dir, name+"_*.tar.gz"))
+	var best, bestVersion string
+	for _, m := range matches {
+		v := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(m), name+"_"), ".tar.gz")
+		if !versionRE.MatchString(v) {
+			continue
+		}
+		if best == "" || compareVersions(v, bestVersion) > 0 {
+			best, bestVersion = m, v
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	abs, err := filepath.Abs(best)
+	if err != nil {
+		return best
+	}
+	return abs
+}
+
+// rVector formats s as an R character vector.
+func rVector(s []string) string {
+	q := make([]string, len(s))
+	for i := range s {
+		q[i] = strconv.Quote(s[i])
+	}
+	return "c(" + strings.Join(q, ", ") + ")"
+}
+
+var (
+	versionRE     = regexp.MustCompile(`^[0-9]+([.-][0-9]+)*$`)
+	requirementRE = regexp.MustCompile(`^\s*([A-Za-z][A-Za-z0-9.]*)\s*(?:\(\s*>=\s*([0-9]+(?:[.-][0-9]+)*)\s*\))?\s*$`)
+)
+
+type requirement struct {
+	name       string
+	minVersion string
+}
+
+// parseRequirement parses a package name with an optional minimum
+// version, written like in the Depends field of a DESCRIPTION file,
+// e.g. "ggplot2 (>= 2.1.0)".
+func parseRequirement(s string) (requirement, error) {
+	m := requirementRE.FindStringSubmatch(s)
+	if m == nil {
+		return requirement{}, errors.Errorf("invalid package requirement %q", s)
+	}
+	return requirement{m[1], m[2]}, nil
+}
+
+// compareVersions compares two R package versions, which are
+// sequences of integers separated by '.' or '-'. It returns -1, 0
+// or 1 if a is less than, equal to or greater than b.
+func compareVersions(a, b string) int {
+	split := func(s string) []string {
+		return strings.FieldsFunc(s, func(r rune) bool { return r == '.' || r == '-' })
+	}
+	as, bs := split(a), split(b)
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x < y {
+			return -1
+		} else if x > y {
+			return 1
+		}
+	}
+	return 0
+}
+
+const installedVersionsStr = `..rgo.req.versions <- vapply(..rgo.req.names, function(p) {
+	tryCatch(as.character(packageVersion(p)), error=function(e) "")
+}, "", USE.NAMES=FALSE)`
+
+// installedVersions returns the installed version of each package,
+// or "" for packages that are not installed.
+func (c *Conn) installedVersions(names []string) ([]string, error) {
+	if err := c.Send(names, "..rgo.req.names"); err != nil {
+		return nil, err
+	}
+	if err := c.R(installedVersionsStr); err != nil {
+		return nil, err
+	}
+	var versions []string
+	if err := c.Get(&versions, "..rgo.req.versions"); err != nil {
+		return nil, err
+	}
+	if len(versions) != len(names) {
+		return nil, errors.Errorf("got %d versions for %d packages", len(versions), len(names))
+	}
+	return versions, nil
+}
+
+// unmet returns the requirements that are not satisfied by the
+// installed packages.
+func (c *Conn) unmet(reqs []requirement) ([]requirement, error) {
+	names := make([]string, len(reqs))
+	for i := range reqs {
+		names[i] = reqs[i].name
+	}
+	versions, err := c.installedVersions(names)
+	if err != nil {
+		return nil, err
+	}
+	var unmet []requirement
+	for i, r := range reqs {
+		if versions[i] == "" || (r.minVersion != "" && compareVersions(versions[i], r.minVersion) < 0) {
+			unmet = append(unmet, r)
+		}
+	}
+	return unmet, nil
+}
+
+// Require loads the given packages. Each package may specify a
+// minimum version, e.g. "ggplot2 (>= 2.1.0)". If any package is
+// missing or too old, and cannot be installed using the Installer
+// set by WithInstaller, a DependencyError listing all of them is
+// returned and no packages are loaded.
+func (c *Conn) Require(pkgs ...string) error {
+	if c.err != nil {
+		return c.err
+	}
+	if len(pkgs) == 0 {
+		return nil
+	}
+	reqs := make([]requirement, len(pkgs))
+	for i, p := range pkgs {
+		var err error
+		if reqs[i], err = parseRequirement(p); err != nil {
+			return err
+		}
+	}
+	unmet, err := c.unmet(reqs)
+	if err != nil {
+		return errors.Wrap(err, "failed to check installed packages")
+	}
+	if len(unmet) > 0 && c.installer != nil {
+		names := make([]string, len(unmet))
+		for i := range unmet {
+			names[i] = unmet[i].name
+		}
+		if err := c.installer.install(c, names); err != nil {
+			return err
+		}
+		if unmet, err = c.unmet(unmet); err != nil {
+			return errors.Wrap(err, "failed to check installed packages")
+		}
+	}
+	if len(unmet) > 0 {
+		deps := make([]string, len(unmet))
+		for i, r := range unmet {
+			deps[i] = r.name
+			if r.minVersion != "" {
+				deps[i] = fmt.Sprintf("%s (>= %s)", r.name, r.minVersion)
+			}
+		}
+		return depError{deps}
+	}
+	for _, r := range reqs {
+		err := c.Rf("suppressPackageStartupMessages(library(%q, character.only=TRUE))", r.name)
+		if err != nil {
+			return errors.Wrapf(err, "failed to load %s", r.name)
+		}
+	}
+	return nil
+}
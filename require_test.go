@@ -0,0 +1,92 @@
+package rgo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseRequirement(t *testing.T) {
+	testCases := []struct {
+		In   string
+		Want requirement
+		Err  bool
+	}{
+		{"jsonlite", requirement{"jsonlite", ""}, false},
+		{" data.table ", requirement{"data.table", ""}, false},
+		{"ggplot2 (>= 2.1.0)", requirement{"ggplot2", "2.1.0"}, false},
+		{"RCurl(>=1.95-4)", requirement{"RCurl", "1.95-4"}, false},
+		{"ggplot2 (> 2.1.0)", requirement{}, true},
+		{"2fast", requirement{}, true},
+		{"", requirement{}, true},
+	}
+	for _, c := range testCases {
+		r, err := parseRequirement(c.In)
+		if (err != nil) != c.Err {
+			t.Errorf("parseRequirement(%q): expected error: %t, got %v", c.In, c.Err, err)
+		}
+		if err == nil && r != c.Want {
+			t.Errorf("parseRequirement(%q): expected %+v, got %+v", c.In, c.Want, r)
+		}
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	testCases := []struct {
+		A, B string
+		Want int
+	}{
+		{"1.0", "1.0", 0},
+		{"1.0", "1.0.0", 0},
+		{"1.2", "1.10", -1},
+		{"1.95-4", "1.95.3", 1},
+		{"2", "1.99", 1},
+	}
+	for _, c := range testCases {
+		if got := compareVersions(c.A, c.B); got != c.Want {
+			t.Errorf("compareVersions(%q, %q): expected %d, got %d", c.A, c.B, c.Want, got)
+		}
+	}
+}
+
+func TestFindTarball(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rgo-src")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	for _, f := range []string{"pkg_1.2.tar.gz", "pkg_1.10.tar.gz", "pkg_x.tar.gz", "pkg.extra_9.0.tar.gz"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, f), nil, 0644); err != nil {
+			t.Fatalf("failed to create %s: %v", f, err)
+		}
+	}
+	if got, want := findTarball(dir, "pkg"), filepath.Join(dir, "pkg_1.10.tar.gz"); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	if got := findTarball(dir, "other"); got != "" {
+		t.Errorf("expected no tarball, got %q", got)
+	}
+}
+
+func TestRequire(t *testing.T) {
+	c := newTestConn(t)
+	defer c.Close()
+
+	if err := c.Require("jsonlite (>= 0.1)", "stats"); err != nil {
+		t.Errorf("unexpected error requiring installed packages: %v", err)
+	}
+	err := c.Require("jsonlite (>= 999)", "rgo.no.such.package")
+	depErr, ok := err.(DependencyError)
+	if !ok {
+		t.Fatalf("expected DependencyError, got %v", err)
+	}
+	want := []string{"jsonlite (>= 999)", "rgo.no.such.package"}
+	if !reflect.DeepEqual(depErr.MissingDependencies(), want) {
+		t.Errorf("expected missing %v, got %v", want, depErr.MissingDependencies())
+	}
+	if c.Error() != nil {
+		t.Errorf("missing dependencies should not stop later operations: %v", c.Error())
+	}
+}
@@ -0,0 +1,169 @@
+/*
+Package rgoexpvar exports statistics about the commands run by rgo
+through the expvar package.
+*/
+package rgoexpvar
+
+import (
+	"expvar"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/uluyol/rgo"
+)
+
+// LatencyBuckets are the upper bounds of the buckets used for
+// the command latency histogram. Changes only affect Hooks created
+// afterwards.
+var LatencyBuckets = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	5 * time.Second,
+	10 * time.Second,
+	time.Minute,
+}
+
+// ByteBuckets are the upper bounds of the buckets used for the
+// histogram of bytes transferred by a command. Changes only affect
+// Hooks created afterwards.
+var ByteBuckets = []int64{
+	1 << 10,
+	16 << 10,
+	256 << 10,
+	1 << 20,
+	16 << 20,
+	256 << 20,
+}
+
+// Hook is an rgo.Hook that records counters and histograms in
+// an expvar.Map. It may be shared by multiple Conns.
+type Hook struct {
+	m *expvar.Map
+
+	commands      *expvar.Int
+	warnings      *expvar.Int
+	errors        *expvar.Int
+	bytesSent     *expvar.Int
+	bytesReceived *expvar.Int
+	userSeconds   *expvar.Float
+	systemSeconds *expvar.Float
+
+	latencyBounds []time.Duration
+	byteBounds    []int64
+	latency       *histogram
+	transfers     *histogram
+}
+
+var _ rgo.Hook = (*Hook)(nil)
+
+// New creates a Hook whose statistics are published under name.
+// Like expvar.Publish, New panics if name is already in use.
+func New(name string) *Hook {
+	h := &Hook{
+		m:             new(expvar.Map).Init(),
+		commands:      new(expvar.Int),
+		warnings:      new(expvar.Int),
+		errors:        new(expvar.Int),
+		bytesSent:     new(expvar.Int),
+		bytesReceived: new(expvar.Int),
+		userSeconds:   new(expvar.Float),
+		systemSeconds: new(expvar.Float),
+		latencyBounds: append([]time.Duration(nil), LatencyBuckets...),
+		byteBounds:    append([]int64(nil), ByteBuckets...),
+	}
+	latencyLabels := make([]string, len(h.latencyBounds))
+	for i, b := range h.latencyBounds {
+		latencyLabels[i] = b.String()
+	}
+	h.latency = newHistogram(latencyLabels)
+	byteLabels := make([]string, len(h.byteBounds))
+	for i, b := range h.byteBounds {
+		byteLabels[i] = strconv.FormatInt(b, 10)
+	}
+	h.transfers = newHistogram(byteLabels)
+
+	h.m.Set("commands", h.commands)
+	h.m.Set("warnings", h.warnings)
+	h.m.Set("errors", h.errors)
+	h.m.Set("bytes_sent", h.bytesSent)
+	h.m.Set("bytes_received", h.bytesReceived)
+	h.m.Set("cpu_user_seconds", h.userSeconds)
+	h.m.Set("cpu_system_seconds", h.systemSeconds)
+	h.m.Set("latency", h.latency)
+	h.m.Set("transfer_bytes", h.transfers)
+	expvar.Publish(name, h.m)
+	return h
+}
+
+// Map returns the map that holds the Hook's statistics.
+func (h *Hook) Map() *expvar.Map { return h.m }
+
+func (h *Hook) OnCommandStart(cmd string) {}
+
+func (h *Hook) OnCommandEnd(s rgo.CommandStats) {
+	h.commands.Add(1)
+	switch s.Result {
+	case rgo.ResultWarning:
+		h.warnings.Add(1)
+	case rgo.ResultError:
+		h.errors.Add(1)
+	}
+	h.bytesSent.Add(s.BytesSent)
+	h.bytesReceived.Add(s.BytesReceived)
+	h.userSeconds.Add(s.UserTime.Seconds())
+	h.systemSeconds.Add(s.SystemTime.Seconds())
+
+	i := 0
+	for i < len(h.latencyBounds) && s.Duration > h.latencyBounds[i] {
+		i++
+	}
+	h.latency.observe(i, s.Duration.Seconds())
+
+	n := s.BytesSent + s.BytesReceived
+	i = 0
+	for i < len(h.byteBounds) && n > h.byteBounds[i] {
+		i++
+	}
+	h.transfers.observe(i, float64(n))
+}
+
+// histogram is an expvar.Var that counts observations in buckets.
+// Buckets are labeled by their upper bound and are not cumulative.
+// Observations larger than every bound are counted in "+Inf".
+type histogram struct {
+	labels []string
+	counts []int64
+	count  int64
+	sum    expvar.Float
+}
+
+func newHistogram(labels []string) *histogram {
+	return &histogram{
+		labels: append(labels, "+Inf"),
+		counts: make([]int64, len(labels)+1),
+	}
+}
+
+func (h *histogram) observe(bucket int, v float64) {
+	atomic.AddInt64(&h.counts[bucket], 1)
+	atomic.AddInt64(&h.count, 1)
+	h.sum.Add(v)
+}
+
+func (h *histogram) String() string {
+	s := fmt.Sprintf(`{"count": %d, "sum": %s, "buckets": {`, atomic.LoadInt64(&h.count), h.sum.String())
+	for i, l := range h.labels {
+		if i > 0 {
+			s += ", "
+		}
+		s += fmt.Sprintf("%q: %d", l, atomic.LoadInt64(&h.counts[i]))
+	}
+	return s + "}}"
+}
@@ -0,0 +1,78 @@
+package rgoexpvar
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/uluyol/rgo"
+)
+
+func TestHook(t *testing.T) {
+	h := New("rgoexpvar_test")
+	h.OnCommandStart("x <- 1")
+	h.OnCommandEnd(rgo.CommandStats{
+		Cmd:       "x <- 1",
+		Duration:  3 * time.Millisecond,
+		BytesSent: 2000,
+		Result:    rgo.ResultOK,
+		UserTime:  time.Second,
+	})
+	h.OnCommandEnd(rgo.CommandStats{
+		Cmd:      "warning('a')",
+		Duration: 2 * time.Minute,
+		Result:   rgo.ResultWarning,
+		Err:      errors.New("a"),
+	})
+
+	var got struct {
+		Commands    int64   `json:"commands"`
+		Warnings    int64   `json:"warnings"`
+		Errors      int64   `json:"errors"`
+		BytesSent   int64   `json:"bytes_sent"`
+		UserSeconds float64 `json:"cpu_user_seconds"`
+		Latency     struct {
+			Count   int64            `json:"count"`
+			Buckets map[string]int64 `json:"buckets"`
+		} `json:"latency"`
+		Transfers struct {
+			Buckets map[string]int64 `json:"buckets"`
+		} `json:"transfer_bytes"`
+	}
+	if err := json.Unmarshal([]byte(h.Map().String()), &got); err != nil {
+		t.Fatalf("failed to decode expvar output %s: %v", h.Map(), err)
+	}
+	if got.Commands != 2 || got.Warnings != 1 || got.Errors != 0 {
+		t.Errorf("expected 2 commands, 1 warning and 0 errors, got %d, %d and %d",
+			got.Commands, got.Warnings, got.Errors)
+	}
+	if got.BytesSent != 2000 || got.UserSeconds != 1 {
+		t.Errorf("expected 2000 bytes sent and 1s CPU time, got %d and %v", got.BytesSent, got.UserSeconds)
+	}
+	if got.Latency.Count != 2 || got.Latency.Buckets["5ms"] != 1 || got.Latency.Buckets["+Inf"] != 1 {
+		t.Errorf("unexpected latency histogram: %+v", got.Latency)
+	}
+	if got.Transfers.Buckets["16384"] != 1 || got.Transfers.Buckets["1024"] != 1 {
+		t.Errorf("unexpected transfer histogram: %+v", got.Transfers)
+	}
+}
+
+func TestHookBucketsChanged(t *testing.T) {
+	h := New("rgoexpvar_test_buckets")
+	defer func(b []time.Duration) { LatencyBuckets = b }(LatencyBuckets)
+	LatencyBuckets = append(LatencyBuckets, time.Hour)
+	h.OnCommandEnd(rgo.CommandStats{Duration: 2 * time.Minute})
+
+	var got struct {
+		Latency struct {
+			Buckets map[string]int64 `json:"buckets"`
+		} `json:"latency"`
+	}
+	if err := json.Unmarshal([]byte(h.Map().String()), &got); err != nil {
+		t.Fatalf("failed to decode expvar output %s: %v", h.Map(), err)
+	}
+	if got.Latency.Buckets["+Inf"] != 1 {
+		t.Errorf("expected observation in +Inf bucket, got %+v", got.Latency)
+	}
+}
@@ -0,0 +1,227 @@
+/*
+Package rgotest provides utilities for testing code that uses rgo.
+
+Fake is an rgo.Executor that does not need R, which allows code
+written against rgo.Executor to be unit tested. Tests that need a
+real R process can use NewConn, which skips the test if R is not
+available.
+*/
+package rgotest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/uluyol/rgo"
+	"github.com/uluyol/rgo/dataframe"
+)
+
+// RequireR skips the test if the R binary is not in the PATH.
+func RequireR(t testing.TB) {
+	if _, err := exec.LookPath("R"); err != nil {
+		t.Skip("R is not installed")
+	}
+}
+
+// NewConn creates a *rgo.Conn for the test. The test is skipped if R
+// or the R packages that rgo depends on are not installed.
+func NewConn(t testing.TB, opts ...rgo.ConnOption) *rgo.Conn {
+	RequireR(t)
+	c, err := rgo.Connection(opts...)
+	if depErr, ok := err.(rgo.DependencyError); ok {
+		t.Skipf("missing R dependencies: %s", strings.Join(depErr.MissingDependencies(), ", "))
+	}
+	if err != nil {
+		t.Fatalf("failed to create connection: %v", err)
+	}
+	return c
+}
+
+type rError string
+
+func (e rError) Error() string { return string(e) }
+func (e rError) IsError()      {}
+
+type rWarning string
+
+func (w rWarning) Error() string { return string(w) }
+func (w rWarning) IsWarning()    {}
+
+// Error returns an rgo.RError with the given message.
+func Error(msg string) error { return rError(msg) }
+
+// Warning returns an rgo.RWarning with the given message.
+func Warning(msg string) error { return rWarning(msg) }
+
+// Expectation describes a command that a Fake expects to run.
+type Expectation struct {
+	re      *regexp.Regexp
+	repeat  bool
+	used    bool
+	err     error
+	setVars map[string]interface{}
+}
+
+// Return makes the command return err, which should typically be
+// created using Error or Warning.
+func (e *Expectation) Return(err error) *Expectation {
+	e.err = err
+	return e
+}
+
+// Set makes the command set the variable name to val. Later calls
+// to Get will return val.
+func (e *Expectation) Set(name string, val interface{}) *Expectation {
+	if e.setVars == nil {
+		e.setVars = make(map[string]interface{})
+	}
+	e.setVars[name] = val
+	return e
+}
+
+// Fake is an rgo.Executor that runs commands against a list of
+// expectations instead of R. Like rgo.Conn, a Fake stops at the
+// first error and returns it from all later operations.
+//
+// The data passed to Send and SendDF is stored and can be retrieved
+// with Get or Sent.
+type Fake struct {
+	// Cmds holds every command that was run.
+	Cmds []string
+
+	expect []*Expectation
+	vars   map[string]interface{}
+	err    error
+	closed bool
+}
+
+var _ rgo.Executor = (*Fake)(nil)
+
+// NewFake creates a Fake with no expectations.
+func NewFake() *Fake {
+	return &Fake{vars: make(map[string]interface{})}
+}
+
+// Expect adds an expectation for a single command matching pattern,
+// a regular expression. Expectations are matched in the order they
+// were added.
+func (f *Fake) Expect(pattern string) *Expectation {
+	e := &Expectation{re: regexp.MustCompile(pattern)}
+	f.expect = append(f.expect, e)
+	return e
+}
+
+// Allow is like Expect but the expectation can match any number of
+// commands, including none.
+func (f *Fake) Allow(pattern string) *Expectation {
+	e := f.Expect(pattern)
+	e.repeat = true
+	return e
+}
+
+// Unmet returns an error listing every expectation added with
+// Expect that has not been matched by a command.
+func (f *Fake) Unmet() error {
+	var unmet []string
+	for _, e := range f.expect {
+		if !e.repeat && !e.used {
+			unmet = append(unmet, e.re.String())
+		}
+	}
+	if len(unmet) > 0 {
+		return errors.Errorf("unmet expectations: %s", strings.Join(unmet, ", "))
+	}
+	return nil
+}
+
+// R matches cmd against the expectations. It is an error if none
+// match.
+func (f *Fake) R(cmd string) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.Cmds = append(f.Cmds, cmd)
+	for _, e := range f.expect {
+		if (e.used && !e.repeat) || !e.re.MatchString(cmd) {
+			continue
+		}
+		e.used = true
+		for name, val := range e.setVars {
+			f.vars[name] = val
+		}
+		if rgo.IsWarning(e.err) {
+			return e.err
+		}
+		f.err = e.err
+		return f.err
+	}
+	f.err = errors.Errorf("unexpected command: %s", cmd)
+	return f.err
+}
+
+// Rf is like R but takes a format string and arguments.
+func (f *Fake) Rf(format string, args ...interface{}) error {
+	return f.R(fmt.Sprintf(format, args...))
+}
+
+// Send stores data as name.
+func (f *Fake) Send(data interface{}, name string) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.vars[name] = data
+	return nil
+}
+
+// SendDF stores df as name.
+func (f *Fake) SendDF(df dataframe.DataFrame, name string) error {
+	return f.Send(df, name)
+}
+
+// Sent returns the data stored as name and whether it exists.
+func (f *Fake) Sent(name string) (interface{}, bool) {
+	v, ok := f.vars[name]
+	return v, ok
+}
+
+// Get copies the data stored as name into data. Like rgo.Conn, the
+// data is transferred by serializing it to json.
+func (f *Fake) Get(data interface{}, name string) error {
+	if f.err != nil {
+		return f.err
+	}
+	v, ok := f.vars[name]
+	if !ok {
+		f.err = rError(fmt.Sprintf("object '%s' not found", name))
+		return f.err
+	}
+	b, err := json.Marshal(v)
+	if err == nil {
+		err = json.Unmarshal(b, data)
+	}
+	if err != nil {
+		f.err = errors.Wrap(err, "error transferring data")
+	}
+	return f.err
+}
+
+// Error returns the first error that occured.
+func (f *Fake) Error() error {
+	return f.err
+}
+
+// Close marks the Fake as closed.
+func (f *Fake) Close() error {
+	f.closed = true
+	return nil
+}
+
+// Closed returns whether Close has been called.
+func (f *Fake) Closed() bool {
+	return f.closed
+}
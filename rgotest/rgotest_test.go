@@ -0,0 +1,77 @@
+package rgotest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/uluyol/rgo"
+)
+
+func TestFake(t *testing.T) {
+	f := NewFake()
+	f.Expect(`^x <- mean\(y\)$`).Set("x", []float64{2})
+	f.Expect(`^warn`).Return(Warning("careful"))
+	f.Allow(`^print\(`)
+	f.Expect(`^never`)
+
+	if err := f.Send([]float64{1, 2, 3}, "y"); err != nil {
+		t.Errorf("unexpected error sending data: %v", err)
+	}
+	if err := f.R("x <- mean(y)"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	var x []float64
+	if err := f.Get(&x, "x"); err != nil || !reflect.DeepEqual(x, []float64{2}) {
+		t.Errorf("expected [2], got %v (err: %v)", x, err)
+	}
+	var y []int
+	if err := f.Get(&y, "y"); err != nil || !reflect.DeepEqual(y, []int{1, 2, 3}) {
+		t.Errorf("expected [1 2 3], got %v (err: %v)", y, err)
+	}
+	if err := f.Rf("warn(%d)", 1); !rgo.IsWarning(err) {
+		t.Errorf("expected warning, got %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := f.R("print(x)"); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if f.Unmet() == nil {
+		t.Errorf("expected unmet expectation")
+	}
+	if err := f.R("x <- mean(y)"); err == nil {
+		t.Errorf("expected error for command matching a used expectation")
+	}
+	if err := f.R("print(x)"); err == nil {
+		t.Errorf("expected errors to be sticky")
+	}
+	want := []string{"x <- mean(y)", "warn(1)", "print(x)", "print(x)", "x <- mean(y)"}
+	if !reflect.DeepEqual(f.Cmds, want) {
+		t.Errorf("expected commands %q, got %q", want, f.Cmds)
+	}
+}
+
+func TestFakeErrors(t *testing.T) {
+	f := NewFake()
+	f.Expect("stop").Return(Error("boom"))
+	if err := f.R("stop('boom')"); !rgo.IsError(err) {
+		t.Errorf("expected RError, got %v", err)
+	}
+	if f.Error() == nil {
+		t.Errorf("expected error to be recorded")
+	}
+
+	f = NewFake()
+	var x []float64
+	if err := f.Get(&x, "missing"); !rgo.IsError(err) {
+		t.Errorf("expected RError getting missing variable, got %v", err)
+	}
+}
+
+func TestNewConn(t *testing.T) {
+	c := NewConn(t)
+	defer c.Close()
+	if err := c.R("x <- 1"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
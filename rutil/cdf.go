@@ -0,0 +1,151 @@
+package rutil
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/uluyol/rgo"
+)
+
+// Sample is a named set of values, e.g. the latencies measured for
+// one configuration.
+type Sample struct {
+	Name   string
+	Values []float64
+}
+
+// CDFOpts configures ECDF and CCDF.
+type CDFOpts struct {
+	// Markers are percentiles (e.g. 50 and 99) to mark on every
+	// curve.
+	Markers []float64
+	// Quantiles makes ECDF and CCDF return the value of each
+	// sample at each of the Markers.
+	Quantiles bool
+	// Legend is the position of the legend for named samples.
+	// Use "none" to omit it.
+	Legend string
+	// Cfg holds additional arguments for the plot. The "col",
+	// "lwd" and "pch" arguments apply to each curve.
+	Cfg GraphCfg
+}
+
+const cdfStr = `local({
+	s <- go.cdf
+	x <- lapply(s, function(v) sort(unique(v)))
+	y <- lapply(seq_along(s), function(i) %[1]s)%[2]s
+	plot(NA%[3]s)
+	for (i in seq_along(s)) {
+		lines(x[[i]], y[[i]], type="s", lty=i%[4]s)
+	}%[5]s%[6]s
+})`
+
+const cdfMarkersStr = `
+	p <- %[1]s
+	q <- lapply(s, quantile, probs=p, type=1, names=FALSE)
+	go.cdf.q <<- q
+	for (i in seq_along(s)) {
+		points(q[[i]], %[2]s%[3]s)
+		text(q[[i]], %[2]s, labels=%[4]s, pos=4, cex=0.7)
+	}`
+
+// ccdfFilterStr drops the points that cannot be drawn on log axes.
+const ccdfFilterStr = `
+	for (i in seq_along(s)) {
+		k <- x[[i]] > 0 & y[[i]] > 0
+		x[[i]] <- x[[i]][k]
+		y[[i]] <- y[[i]][k]
+	}`
+
+// ECDF plots the empirical CDF of each sample. Each sample is drawn
+// with a different line type.
+//
+// If opts.Quantiles is set, ECDF returns the values of the samples at
+// opts.Markers: the value of sample i at marker j is in [i][j].
+func ECDF(rc rgo.Executor, samples []Sample, opts CDFOpts) ([][]float64, error) {
+	return cdfPlot(rc, samples, opts, false)
+}
+
+// CCDF is like ECDF but plots the complementary CDF, 1 - F(x), on
+// log axes unless opts.Cfg sets "log". Points that cannot be shown
+// on log axes are dropped.
+func CCDF(rc rgo.Executor, samples []Sample, opts CDFOpts) ([][]float64, error) {
+	return cdfPlot(rc, samples, opts, true)
+}
+
+func cdfPlot(rc rgo.Executor, samples []Sample, opts CDFOpts, comp bool) ([][]float64, error) {
+	if err := opts.Cfg.Err(); err != nil {
+		return nil, err
+	}
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("no samples to plot")
+	}
+	vals := make([][]float64, len(samples))
+	var names []string
+	for i, s := range samples {
+		if len(s.Values) == 0 {
+			return nil, fmt.Errorf("sample %d (%q) is empty", i, s.Name)
+		}
+		vals[i] = s.Values
+		if s.Name != "" {
+			names = append(names, s.Name)
+		}
+	}
+	if len(names) != 0 && len(names) != len(samples) {
+		return nil, fmt.Errorf("either all or no samples must be named")
+	}
+	if opts.Quantiles && len(opts.Markers) == 0 {
+		return nil, fmt.Errorf("quantiles requested without markers")
+	}
+	probs := make([]float64, len(opts.Markers))
+	labels := make([]string, len(opts.Markers))
+	for i, m := range opts.Markers {
+		if m <= 0 || m > 100 {
+			return nil, fmt.Errorf("invalid percentile %v", m)
+		}
+		probs[i] = m / 100
+		labels[i] = "p" + strconv.FormatFloat(m, 'f', -1, 64)
+	}
+
+	series, cfg := opts.Cfg.split("col", "lwd", "pch")
+	yfn, filter, pos := "ecdf(s[[i]])(x[[i]])", "", "bottomright"
+	frame := GraphCfg{}.WithRaw("xlim", "range(unlist(x))")
+	if comp {
+		yfn, filter, pos = "1 - "+yfn, ccdfFilterStr, "topright"
+		frame = frame.WithRaw("ylim", "range(unlist(y))").WithXLab("Value").WithYLab("CCDF").WithLog("xy")
+	} else {
+		frame = frame.WithYLim(0, 1).WithXLab("Value").WithYLab("CDF")
+	}
+	frame = frame.update(cfg)
+
+	_, lineArgs := series.split("pch")
+	markers := ""
+	if len(probs) > 0 {
+		my := "p"
+		if comp {
+			my = "1 - p"
+		}
+		pointArgs := GraphCfg{}.WithPch(19).update(series)
+		markers = fmt.Sprintf(cdfMarkersStr, rNums(probs), my, pointArgs.params(), rStrs(labels))
+	}
+	legend := ""
+	if len(names) > 0 && opts.Legend != "none" {
+		if opts.Legend != "" {
+			pos = opts.Legend
+		}
+		legend = fmt.Sprintf("\n\tlegend(%s, legend=%s, lty=seq_along(s)%s)", strconv.Quote(pos), rStrs(names), lineArgs.params())
+	}
+
+	if err := sendList(rc, vals, "go.cdf"); err != nil {
+		return nil, err
+	}
+	err := rc.Rf(cdfStr, yfn, filter, frame.params(), lineArgs.params(), markers, legend)
+	if err != nil || !opts.Quantiles {
+		return nil, err
+	}
+	var q [][]float64
+	if err := rc.Get(&q, "go.cdf.q"); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
@@ -0,0 +1,86 @@
+package rutil
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/uluyol/rgo/rgotest"
+)
+
+func TestECDF(t *testing.T) {
+	f := rgotest.NewFake()
+	f.Allow(".*")
+	samples := []Sample{
+		{Name: "a", Values: []float64{1, 2, 3}},
+		{Name: "b", Values: []float64{2, 4}},
+	}
+	q, err := ECDF(f, samples, CDFOpts{Cfg: GraphCfg{}.WithLwd(2).WithXLab("Latency (ms)")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if q != nil {
+		t.Errorf("expected no quantiles, got %v", q)
+	}
+	want := []string{
+		`go.cdf <- lapply(list(go.cdf.0, go.cdf.1), as.double)`,
+		`local({
+	s <- go.cdf
+	x <- lapply(s, function(v) sort(unique(v)))
+	y <- lapply(seq_along(s), function(i) ecdf(s[[i]])(x[[i]]))
+	plot(NA, xlim=range(unlist(x)), ylim=c(0, 1), xlab="Latency (ms)", ylab="CDF")
+	for (i in seq_along(s)) {
+		lines(x[[i]], y[[i]], type="s", lty=i, lwd=2)
+	}
+	legend("bottomright", legend=c("a", "b"), lty=seq_along(s), lwd=2)
+})`,
+	}
+	if !reflect.DeepEqual(f.Cmds, want) {
+		t.Errorf("expected commands\n%s\ngot\n%s", want, f.Cmds)
+	}
+	if sent, _ := f.Sent("go.cdf.1"); !reflect.DeepEqual(sent, samples[1].Values) {
+		t.Errorf("expected second sample to be sent, got %v", sent)
+	}
+}
+
+func TestCCDFQuantiles(t *testing.T) {
+	f := rgotest.NewFake()
+	f.Allow(`^go\.cdf <-`)
+	f.Expect(`(?s)^local\(.*1 - ecdf.*k <- x\[\[i\]\] > 0.*log="xy".*p <- c\(0.5, 0.99\).*points\(q\[\[i\]\], 1 - p, pch=19\).*labels=c\("p50", "p99"\)`).
+		Set("go.cdf.q", [][]float64{{2, 3}})
+	q, err := CCDF(f, []Sample{{Values: []float64{1, 2, 3}}}, CDFOpts{Markers: []float64{50, 99}, Quantiles: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := [][]float64{{2, 3}}; !reflect.DeepEqual(q, want) {
+		t.Errorf("expected quantiles %v, got %v", want, q)
+	}
+	if err := f.Unmet(); err != nil {
+		t.Error(err)
+	}
+	if len(f.Cmds) != 2 || strings.Contains(f.Cmds[1], "legend") {
+		t.Errorf("expected no legend for unnamed samples, got %q", f.Cmds)
+	}
+}
+
+func TestCDFInvalid(t *testing.T) {
+	bad := []struct {
+		samples []Sample
+		opts    CDFOpts
+	}{
+		{nil, CDFOpts{}},
+		{[]Sample{{Name: "a"}}, CDFOpts{}},
+		{[]Sample{{Name: "a", Values: []float64{1}}, {Values: []float64{1}}}, CDFOpts{}},
+		{[]Sample{{Values: []float64{1}}}, CDFOpts{Markers: []float64{0}}},
+		{[]Sample{{Values: []float64{1}}}, CDFOpts{Quantiles: true}},
+	}
+	for i, c := range bad {
+		f := rgotest.NewFake()
+		if _, err := ECDF(f, c.samples, c.opts); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+		if len(f.Cmds) != 0 {
+			t.Errorf("case %d: expected no commands, got %q", i, f.Cmds)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package rutil
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/uluyol/rgo"
+	"github.com/uluyol/rgo/dataframe"
+)
+
+// DFPlot selects the columns of a data frame to plot.
+type DFPlot struct {
+	X, Y string
+	// Group is optional. If set, a separate series is drawn for
+	// each distinct value of the column.
+	Group string
+	// Legend is the position of the legend for groups, by default
+	// "topright". Use "none" to omit it.
+	Legend string
+}
+
+// seriesArgs are the arguments of GraphCfg that apply to each
+// series rather than the whole plot.
+var seriesArgs = []string{"type", "col", "lwd", "lty", "pch", "cex"}
+
+func checkCols(df dataframe.DataFrame, cols ...string) error {
+	names := df.ColNames()
+	for _, c := range cols {
+		found := false
+		for _, n := range names {
+			if n == c {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("data frame has no column %q", c)
+		}
+	}
+	return nil
+}
+
+// checkNumeric checks that the columns cols of df only hold numbers.
+func checkNumeric(df dataframe.DataFrame, cols ...string) error {
+	for _, c := range cols {
+		col := df.Col(c)
+		for i := 0; i < col.Len(); i++ {
+			v := col.GetIndexSD(i)
+			switch reflect.ValueOf(v).Kind() {
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+				reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+				reflect.Float32, reflect.Float64:
+			default:
+				return fmt.Errorf("column %q is not numeric: row %d holds %T", c, i, v)
+			}
+		}
+	}
+	return nil
+}
+
+const plotGroupsStr = `local({
+	d <- go.df
+	g <- factor(d[[%[1]s]])
+	lv <- levels(g)
+	cols <- seq_along(lv)
+	plot(d[[%[2]s]], d[[%[3]s]], type="n"%[4]s)
+	for (i in seq_along(lv)) {
+		s <- d[!is.na(g) & g == lv[i], , drop=FALSE]
+		points(s[[%[2]s]], s[[%[3]s]], col=cols[i]%[5]s)
+	}
+	%[6]s
+})`
+
+// PlotDF plots two columns of df against each other. The data frame
+// is sent to R once as go.df. The axes are labeled with the column
+// names unless cfg sets xlab or ylab.
+//
+// When p.Group is set, each group is drawn in a different color and
+// a legend is added. In this case, the "col" argument of cfg is
+// ignored and the "type", "lwd", "lty", "pch" and "cex" arguments
+// apply to each series.
+func PlotDF(rc rgo.Executor, df dataframe.DataFrame, p DFPlot, cfg GraphCfg) error {
+	if err := cfg.Err(); err != nil {
+		return err
+	}
+	cols := []string{p.X, p.Y}
+	if p.Group != "" {
+		cols = append(cols, p.Group)
+	}
+	if err := checkCols(df, cols...); err != nil {
+		return err
+	}
+	if _, ok := cfg.get("xlab"); !ok {
+		cfg = cfg.WithXLab(p.X)
+	}
+	if _, ok := cfg.get("ylab"); !ok {
+		cfg = cfg.WithYLab(p.Y)
+	}
+	if err := rc.SendDF(df, "go.df"); err != nil {
+		return err
+	}
+	x, y := strconv.Quote(p.X), strconv.Quote(p.Y)
+	if p.Group == "" {
+		return rc.Rf("plot(go.df[[%s]], go.df[[%s]]%s)", x, y, cfg.params())
+	}
+
+	series, frame := cfg.split(seriesArgs...)
+	_, series = series.split("col")
+	legend := ""
+	if p.Legend != "none" {
+		pos := p.Legend
+		if pos == "" {
+			pos = "topright"
+		}
+		legend = fmt.Sprintf("legend(%s, legend=lv, col=cols%s)", strconv.Quote(pos), legendSymbols(series).params())
+	}
+	return rc.Rf(plotGroupsStr, strconv.Quote(p.Group), x, y, frame.params(), series.params(), legend)
+}
+
+// legendSymbols returns the arguments for a legend that matches
+// series drawn using cfg.
+func legendSymbols(cfg GraphCfg) GraphCfg {
+	var legend GraphCfg
+	t, _ := cfg.get("type")
+	lines := t == `"l"` || t == `"b"` || t == `"o"`
+	points := t == "" || t == `"p"` || t == `"b"` || t == `"o"`
+	if lines {
+		legend = legend.WithLty(1)
+		if v, ok := cfg.get("lty"); ok {
+			legend = legend.WithRaw("lty", v)
+		}
+		if v, ok := cfg.get("lwd"); ok {
+			legend = legend.WithRaw("lwd", v)
+		}
+	}
+	if points {
+		legend = legend.WithPch(1)
+		if v, ok := cfg.get("pch"); ok {
+			legend = legend.WithRaw("pch", v)
+		}
+	}
+	return legend
+}
@@ -0,0 +1,56 @@
+package rutil
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/uluyol/rgo/dataframe"
+	"github.com/uluyol/rgo/rgotest"
+)
+
+func TestPlotDF(t *testing.T) {
+	df := dataframe.New("size", "latency", "config")
+	df.AppendURow(1.0, 2.0, "a")
+	df.AppendURow(2.0, 3.0, "b")
+
+	f := rgotest.NewFake()
+	f.Allow(".*")
+	err := PlotDF(f, df, DFPlot{X: "size", Y: "latency"}, GraphCfg{}.WithXLab("Size"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{`plot(go.df[["size"]], go.df[["latency"]], xlab="Size", ylab="latency")`}
+	if !reflect.DeepEqual(f.Cmds, want) {
+		t.Errorf("expected commands %q, got %q", want, f.Cmds)
+	}
+	if sent, _ := f.Sent("go.df"); sent != df {
+		t.Errorf("expected data frame to be sent as go.df")
+	}
+
+	f = rgotest.NewFake()
+	f.Allow(".*")
+	cfg := GraphCfg{}.WithType("l").WithLwd(2).WithCol("red").WithMain("Latency")
+	err = PlotDF(f, df, DFPlot{X: "size", Y: "latency", Group: "config"}, cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wantCmd := `local({
+	d <- go.df
+	g <- factor(d[["config"]])
+	lv <- levels(g)
+	cols <- seq_along(lv)
+	plot(d[["size"]], d[["latency"]], type="n", main="Latency", xlab="size", ylab="latency")
+	for (i in seq_along(lv)) {
+		s <- d[!is.na(g) & g == lv[i], , drop=FALSE]
+		points(s[["size"]], s[["latency"]], col=cols[i], type="l", lwd=2)
+	}
+	legend("topright", legend=lv, col=cols, lty=1, lwd=2)
+})`
+	if len(f.Cmds) != 1 || f.Cmds[0] != wantCmd {
+		t.Errorf("expected command\n%s\ngot\n%q", wantCmd, f.Cmds)
+	}
+
+	if err := PlotDF(rgotest.NewFake(), df, DFPlot{X: "size", Y: "nope"}, GraphCfg{}); err == nil {
+		t.Errorf("expected error for missing column")
+	}
+}
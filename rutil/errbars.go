@@ -0,0 +1,177 @@
+package rutil
+
+import (
+	"fmt"
+
+	"github.com/uluyol/rgo"
+)
+
+// sendBounds sends lo and hi as go.lo and go.hi.
+func sendBounds(rc rgo.Executor, lo, hi []float64) error {
+	if err := sendFloats(rc, lo, "go.lo"); err != nil {
+		return err
+	}
+	return sendFloats(rc, hi, "go.hi")
+}
+
+// checkLens returns an error unless all vals have n elements.
+func checkLens(n int, vals ...[]float64) error {
+	for _, v := range vals {
+		if len(v) != n {
+			return fmt.Errorf("got %d values, expected %d", len(v), n)
+		}
+	}
+	return nil
+}
+
+// symBounds returns y-err and y+err.
+func symBounds(y, err []float64) (lo, hi []float64) {
+	lo = make([]float64, len(y))
+	hi = make([]float64, len(y))
+	for i := range y {
+		lo[i] = y[i] - err[i]
+		hi[i] = y[i] + err[i]
+	}
+	return lo, hi
+}
+
+// barArgs are the default arguments for arrows() to draw error bars.
+// Setting "length" to 0 draws plain segments.
+var barArgs = GraphCfg{}.With("angle", 90).With("code", 3).With("length", 0.05)
+
+// ErrorBars adds a bar from lo[i] to hi[i] at each x[i] to the
+// current plot.
+func ErrorBars(rc rgo.Executor, x, lo, hi []float64, cfg GraphCfg) error {
+	if err := cfg.Err(); err != nil {
+		return err
+	}
+	if err := checkLens(len(x), lo, hi); err != nil {
+		return err
+	}
+	if err := sendFloats(rc, x, "go.x"); err != nil {
+		return err
+	}
+	if err := sendBounds(rc, lo, hi); err != nil {
+		return err
+	}
+	return rc.Rf("arrows(go.x, go.lo, go.x, go.hi%s)", barArgs.update(cfg).params())
+}
+
+// SymErrorBars is like ErrorBars but draws bars from y[i]-err[i] to
+// y[i]+err[i].
+func SymErrorBars(rc rgo.Executor, x, y, err []float64, cfg GraphCfg) error {
+	if e := checkLens(len(x), y, err); e != nil {
+		return e
+	}
+	lo, hi := symBounds(y, err)
+	return ErrorBars(rc, x, lo, hi, cfg)
+}
+
+// PlotErr plots y against x with error bars from lo to hi. Unless cfg
+// sets "ylim", the y axis covers all bars. The "col" and "lwd"
+// arguments of cfg also apply to the bars.
+func PlotErr(rc rgo.Executor, x, y, lo, hi []float64, cfg GraphCfg) error {
+	if err := checkLens(len(x), y, lo, hi); err != nil {
+		return err
+	}
+	if _, ok := cfg.get("ylim"); !ok {
+		cfg = cfg.With("ylim", dataRange(y, lo, hi))
+	}
+	if err := Plot(rc, x, y, cfg); err != nil {
+		return err
+	}
+	bars, _ := cfg.split("col", "lwd")
+	return ErrorBars(rc, x, lo, hi, bars)
+}
+
+// Ribbon adds the line y to the current plot along with a shaded
+// band from lo to hi, e.g. a confidence interval. The band is drawn
+// in a translucent version of the line's color.
+func Ribbon(rc rgo.Executor, x, y, lo, hi []float64, cfg GraphCfg) error {
+	if err := cfg.Err(); err != nil {
+		return err
+	}
+	if err := checkLens(len(x), y, lo, hi); err != nil {
+		return err
+	}
+	col, ok := cfg.get("col")
+	if !ok {
+		col = "1"
+	}
+	band := GraphCfg{}.WithRaw("col", fmt.Sprintf("adjustcolor(%s, alpha.f=0.3)", col)).WithRaw("border", "NA")
+	if err := Area(rc, x, lo, hi, band); err != nil {
+		return err
+	}
+	return Lines(rc, x, y, cfg)
+}
+
+// BarPlotErr is like BarPlot but adds an error bar from lo[i] to
+// hi[i] to each bar.
+func BarPlotErr(rc rgo.Executor, heights, lo, hi []float64, names []string, cfg GraphCfg) error {
+	if err := cfg.Err(); err != nil {
+		return err
+	}
+	if err := checkLens(len(heights), lo, hi); err != nil {
+		return err
+	}
+	if names != nil {
+		cfg = cfg.With("names.arg", names)
+	}
+	if _, ok := cfg.get("ylim"); !ok {
+		cfg = cfg.With("ylim", dataRange([]float64{0}, heights, lo, hi))
+	}
+	if err := sendFloats(rc, heights, "go.heights"); err != nil {
+		return err
+	}
+	if err := sendBounds(rc, lo, hi); err != nil {
+		return err
+	}
+	return rc.Rf(barErrStr, "as.double(go.heights)", cfg.params(), "as.double(go.lo)", "as.double(go.hi)", barArgs.params())
+}
+
+// GroupedBarPlotErr is like GroupedBarPlot with bars drawn next to
+// each other and adds an error bar from lo[i][j] to hi[i][j] to each
+// bar.
+func GroupedBarPlotErr(rc rgo.Executor, heights, lo, hi [][]float64, groups, series []string, cfg GraphCfg) error {
+	if err := cfg.Err(); err != nil {
+		return err
+	}
+	if len(lo) != len(heights) || len(hi) != len(heights) {
+		return fmt.Errorf("got bounds for %d and %d series, expected %d", len(lo), len(hi), len(heights))
+	}
+	for i := range heights {
+		if err := checkLens(len(heights[i]), lo[i], hi[i]); err != nil {
+			return fmt.Errorf("series %d: %v", i, err)
+		}
+	}
+	cfg = cfg.With("beside", true)
+	if groups != nil {
+		cfg = cfg.With("names.arg", groups)
+	}
+	if series != nil {
+		cfg = cfg.With("legend.text", series)
+	}
+	if _, ok := cfg.get("ylim"); !ok {
+		all := [][]float64{{0}}
+		all = append(all, lo...)
+		all = append(all, hi...)
+		cfg = cfg.With("ylim", dataRange(all...))
+	}
+	if err := sendMatrix(rc, heights, "go.heights"); err != nil {
+		return err
+	}
+	if err := sendMatrix(rc, lo, "go.lo"); err != nil {
+		return err
+	}
+	if err := sendMatrix(rc, hi, "go.hi"); err != nil {
+		return err
+	}
+	return rc.Rf(barErrStr, "go.heights", cfg.params(), "go.lo", "go.hi", barArgs.params())
+}
+
+// barErrStr draws error bars at the midpoints of the bars returned
+// by barplot.
+const barErrStr = `local({
+	b <- barplot(%[1]s%[2]s)
+	arrows(b, %[3]s, b, %[4]s%[5]s)
+})`
@@ -0,0 +1,63 @@
+package rutil
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/uluyol/rgo/rgotest"
+)
+
+func TestErrorBars(t *testing.T) {
+	f := rgotest.NewFake()
+	f.Allow(".*")
+	x := []float64{1, 2}
+	y := []float64{2, 3}
+	cfg := GraphCfg{}.WithCol("red").WithMain("Throughput")
+
+	SymErrorBars(f, x, y, []float64{0.5, 1}, GraphCfg{}.With("length", 0))
+	PlotErr(f, x, y, []float64{1, 2}, []float64{3, 5}, cfg)
+	Ribbon(f, x, y, []float64{1, 2}, []float64{3, 4}, GraphCfg{}.WithCol("blue"))
+	BarPlotErr(f, y, []float64{1, 2}, []float64{3, 4}, []string{"a", "b"}, GraphCfg{})
+	GroupedBarPlotErr(f, [][]float64{{1, 2}}, [][]float64{{0.5, 1}}, [][]float64{{1.5, 3}}, nil, []string{"s"}, GraphCfg{})
+	if err := f.Error(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{
+		`arrows(go.x, go.lo, go.x, go.hi, angle=90, code=3, length=0)`,
+		`plot(go.x, go.y, col="red", main="Throughput", ylim=c(1, 5))`,
+		`arrows(go.x, go.lo, go.x, go.hi, angle=90, code=3, length=0.05, col="red")`,
+		`polygon(c(go.x, rev(go.x)), c(go.lo, rev(go.hi)), col=adjustcolor("blue", alpha.f=0.3), border=NA)`,
+		`lines(go.x, go.y, col="blue")`,
+		`local({
+	b <- barplot(as.double(go.heights), names.arg=c("a", "b"), ylim=c(0, 4))
+	arrows(b, as.double(go.lo), b, as.double(go.hi), angle=90, code=3, length=0.05)
+})`,
+		`go.heights <- matrix(as.double(go.heights), nrow=1, byrow=TRUE)`,
+		`go.lo <- matrix(as.double(go.lo), nrow=1, byrow=TRUE)`,
+		`go.hi <- matrix(as.double(go.hi), nrow=1, byrow=TRUE)`,
+		`local({
+	b <- barplot(go.heights, beside=TRUE, legend.text=c("s"), ylim=c(0, 3))
+	arrows(b, go.lo, b, go.hi, angle=90, code=3, length=0.05)
+})`,
+	}
+	if !reflect.DeepEqual(f.Cmds, want) {
+		t.Errorf("expected commands\n%q\ngot\n%q", want, f.Cmds)
+	}
+	if lo, _ := f.Sent("go.lo"); !reflect.DeepEqual(lo, []float64{0.5, 1}) {
+		t.Errorf("expected lower bounds to be sent, got %v", lo)
+	}
+}
+
+func TestErrorBarsLength(t *testing.T) {
+	f := rgotest.NewFake()
+	if err := ErrorBars(f, []float64{1, 2}, []float64{1}, []float64{2, 3}, GraphCfg{}); err == nil {
+		t.Errorf("expected error for mismatched lengths")
+	}
+	if err := GroupedBarPlotErr(f, [][]float64{{1}}, nil, nil, nil, nil, GraphCfg{}); err == nil {
+		t.Errorf("expected error for missing bounds")
+	}
+	if len(f.Cmds) != 0 {
+		t.Errorf("expected no commands, got %q", f.Cmds)
+	}
+}
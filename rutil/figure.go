@@ -0,0 +1,285 @@
+package rutil
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/uluyol/rgo"
+)
+
+// Figure describes a complete plot. Unlike the other functions in
+// this package, which run R commands as they are called, a Figure is
+// built up in Go, validated and then drawn by a single R command.
+// Figures can be encoded as JSON to be stored and rendered later.
+// The arguments in the Cfg fields are R code that is run when the
+// figure is rendered, so only render stored figures from trusted
+// sources.
+type Figure struct {
+	Title  string `json:"title,omitempty"`
+	XLabel string `json:"xlabel,omitempty"`
+	YLabel string `json:"ylabel,omitempty"`
+	// XLim and YLim are the ranges of the axes. If they are not
+	// set, the ranges cover all series and annotations.
+	XLim []float64 `json:"xlim,omitempty"`
+	YLim []float64 `json:"ylim,omitempty"`
+	// Log sets which axes use a log scale: "x", "y" or "xy".
+	Log string `json:"log,omitempty"`
+
+	Series      []Series     `json:"series,omitempty"`
+	Annotations []Annotation `json:"annotations,omitempty"`
+	// Axes replace the default axis on their side.
+	Axes   []AxisSpec  `json:"axes,omitempty"`
+	Legend *LegendSpec `json:"legend,omitempty"`
+
+	// Cfg holds additional arguments for plot().
+	Cfg GraphCfg `json:"cfg"`
+}
+
+// Series is a set of points drawn by lines().
+type Series struct {
+	// Name labels the series in the legend. Unnamed series are
+	// left out of the legend.
+	Name string    `json:"name,omitempty"`
+	X    []float64 `json:"x"`
+	Y    []float64 `json:"y"`
+	// Type is R's plot type, e.g. "p" for points (the default),
+	// "l" for lines or "b" for both.
+	Type string `json:"type,omitempty"`
+	// Cfg holds additional arguments for lines(). Series without
+	// a color are colored using the palette.
+	Cfg GraphCfg `json:"cfg"`
+}
+
+// Annotation is text drawn at a point.
+type Annotation struct {
+	X    float64  `json:"x"`
+	Y    float64  `json:"y"`
+	Text string   `json:"text"`
+	Cfg  GraphCfg `json:"cfg"`
+}
+
+// AxisSpec describes an axis. See Axis.
+type AxisSpec struct {
+	Side   Side      `json:"side"`
+	At     []float64 `json:"at,omitempty"`
+	Labels []string  `json:"labels,omitempty"`
+	Cfg    GraphCfg  `json:"cfg"`
+}
+
+// LegendSpec describes a legend for the named series of a Figure.
+type LegendSpec struct {
+	// Pos is the position of the legend, by default "topright".
+	Pos string   `json:"pos,omitempty"`
+	Cfg GraphCfg `json:"cfg"`
+}
+
+// Validate checks that f can be drawn.
+func (f *Figure) Validate() error {
+	cfgs := []GraphCfg{f.Cfg}
+	for _, s := range f.Series {
+		cfgs = append(cfgs, s.Cfg)
+	}
+	for _, a := range f.Annotations {
+		cfgs = append(cfgs, a.Cfg)
+	}
+	for _, a := range f.Axes {
+		cfgs = append(cfgs, a.Cfg)
+	}
+	if f.Legend != nil {
+		cfgs = append(cfgs, f.Legend.Cfg)
+	}
+	if err := cfgErr(cfgs...); err != nil {
+		return err
+	}
+	for _, lim := range [][]float64{f.XLim, f.YLim} {
+		if lim != nil && len(lim) != 2 {
+			return fmt.Errorf("axis limits must have 2 values, got %d", len(lim))
+		}
+		for _, v := range lim {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				return fmt.Errorf("axis limits must be finite, got %v", lim)
+			}
+		}
+	}
+	switch f.Log {
+	case "", "x", "y", "xy", "yx":
+	default:
+		return fmt.Errorf("invalid log axes %q", f.Log)
+	}
+	named := 0
+	for i, s := range f.Series {
+		if len(s.X) != len(s.Y) {
+			return fmt.Errorf("series %d has %d x values but %d y values", i, len(s.X), len(s.Y))
+		}
+		if len(s.X) == 0 {
+			return fmt.Errorf("series %d is empty", i)
+		}
+		if s.Name != "" {
+			named++
+		}
+	}
+	for i, a := range f.Axes {
+		if a.Side < Bottom || a.Side > Right {
+			return fmt.Errorf("axis %d has invalid side %d", i, a.Side)
+		}
+		if a.Labels != nil && len(a.Labels) != len(a.At) {
+			return fmt.Errorf("axis %d has %d labels for %d ticks", i, len(a.Labels), len(a.At))
+		}
+	}
+	if f.Legend != nil && named == 0 {
+		return fmt.Errorf("legend requires at least one named series")
+	}
+	return nil
+}
+
+// dataRange returns the range of the finite values in vals, or
+// [0, 1] if there are none.
+func dataRange(vals ...[]float64) []float64 {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range vals {
+		for _, x := range v {
+			if math.IsNaN(x) || math.IsInf(x, 0) {
+				continue
+			}
+			lo = math.Min(lo, x)
+			hi = math.Max(hi, x)
+		}
+	}
+	if lo > hi {
+		return []float64{0, 1}
+	}
+	return []float64{lo, hi}
+}
+
+// data returns the x and y values drawn in f.
+func (f *Figure) data() (xs, ys [][]float64) {
+	for _, s := range f.Series {
+		xs = append(xs, s.X)
+		ys = append(ys, s.Y)
+	}
+	for _, a := range f.Annotations {
+		xs = append(xs, []float64{a.X})
+		ys = append(ys, []float64{a.Y})
+	}
+	return xs, ys
+}
+
+// code returns the R command that draws f.
+func (f *Figure) code() string {
+	xs, ys := f.data()
+	xlim, ylim := f.XLim, f.YLim
+	if xlim == nil {
+		xlim = dataRange(xs...)
+	}
+	if ylim == nil {
+		ylim = dataRange(ys...)
+	}
+	frame := GraphCfg{}.With("xlim", xlim).With("ylim", ylim).
+		WithXLab(f.XLabel).WithYLab(f.YLabel)
+	if f.Title != "" {
+		frame = frame.WithMain(f.Title)
+	}
+	if f.Log != "" {
+		frame = frame.WithLog(f.Log)
+	}
+	for _, a := range f.Axes {
+		switch a.Side {
+		case Bottom:
+			frame = frame.With("xaxt", "n")
+		case Left:
+			frame = frame.With("yaxt", "n")
+		}
+	}
+	frame = frame.update(f.Cfg)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "local({\n\tplot(NA%s)\n", frame.params())
+	var labels, cols, ltys, pchs []string
+	for i, s := range f.Series {
+		cfg := s.Cfg
+		if s.Type != "" {
+			cfg = cfg.WithType(s.Type)
+		} else if _, ok := cfg.get("type"); !ok {
+			// lines() would otherwise draw a line.
+			cfg = cfg.WithType("p")
+		}
+		if _, ok := cfg.get("col"); !ok {
+			cfg = cfg.With("col", i+1)
+		}
+		fmt.Fprintf(&buf, "\tlines(%s, %s%s)\n", rNums(s.X), rNums(s.Y), cfg.params())
+		if s.Name == "" {
+			continue
+		}
+		sym := legendSymbols(cfg)
+		col, _ := cfg.get("col")
+		lty, ok := sym.get("lty")
+		if !ok {
+			lty = "NA"
+		}
+		pch, ok := sym.get("pch")
+		if !ok {
+			pch = "NA"
+		}
+		labels = append(labels, strconv.Quote(s.Name))
+		cols = append(cols, col)
+		ltys = append(ltys, lty)
+		pchs = append(pchs, pch)
+	}
+	for _, a := range f.Annotations {
+		fmt.Fprintf(&buf, "\ttext(%s, %s, labels=%s%s)\n", rNum(a.X), rNum(a.Y), strconv.Quote(a.Text), a.Cfg.params())
+	}
+	for _, a := range f.Axes {
+		cfg := a.Cfg
+		if a.At != nil {
+			cfg = cfg.With("at", a.At)
+		}
+		if a.Labels != nil {
+			cfg = cfg.With("labels", a.Labels)
+		}
+		fmt.Fprintf(&buf, "\taxis(%d%s)\n", a.Side, cfg.params())
+	}
+	if f.Legend != nil {
+		pos := f.Legend.Pos
+		if pos == "" {
+			pos = "topright"
+		}
+		fmt.Fprintf(&buf, "\tlegend(%s, legend=%s, col=%s, lty=%s, pch=%s%s)\n", strconv.Quote(pos),
+			rVec(labels), rVec(cols), rVec(ltys), rVec(pchs), f.Legend.Cfg.params())
+	}
+	buf.WriteString("})")
+	return buf.String()
+}
+
+// Render validates f and draws it on the current device using a
+// single R command.
+func (f *Figure) Render(rc rgo.Executor) error {
+	if err := f.Validate(); err != nil {
+		return err
+	}
+	return rc.R(f.code())
+}
+
+// RenderTo renders f on a new device described by spec and returns
+// what was drawn.
+func (f *Figure) RenderTo(rc *rgo.Conn, spec rgo.DeviceSpec) ([]byte, error) {
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+	return DrawTo(rc, spec, func() error { return f.Render(rc) })
+}
+
+// DrawTo opens a device described by spec, calls draw and returns
+// what was drawn on the device.
+func DrawTo(rc *rgo.Conn, spec rgo.DeviceSpec, draw func() error) ([]byte, error) {
+	d, err := rc.Device(spec)
+	if err != nil {
+		return nil, err
+	}
+	if err := draw(); err != nil {
+		d.Close()
+		return nil, err
+	}
+	return d.Close()
+}
@@ -0,0 +1,132 @@
+package rutil
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/uluyol/rgo/rgotest"
+)
+
+func testFigure() *Figure {
+	return &Figure{
+		Title:  "Latency",
+		XLabel: "Size",
+		YLabel: "ms",
+		Series: []Series{
+			{Name: "a", X: []float64{1, 2}, Y: []float64{3, 4}, Type: "l"},
+			{X: []float64{0, 5}, Y: []float64{1, 1}, Cfg: GraphCfg{}.WithCol("gray")},
+		},
+		Annotations: []Annotation{{X: 1, Y: 3, Text: "start"}},
+		Axes:        []AxisSpec{{Side: Bottom, At: []float64{1, 2}, Labels: []string{"1K", "2K"}}},
+		Legend:      &LegendSpec{},
+	}
+}
+
+func TestFigureRender(t *testing.T) {
+	f := rgotest.NewFake()
+	f.Allow(".*")
+	if err := testFigure().Render(f); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `local({
+	plot(NA, xlim=c(0, 5), ylim=c(1, 4), xlab="Size", ylab="ms", main="Latency", xaxt="n")
+	lines(c(1, 2), c(3, 4), type="l", col=1)
+	lines(c(0, 5), c(1, 1), col="gray", type="p")
+	text(1, 3, labels="start")
+	axis(1, at=c(1, 2), labels=c("1K", "2K"))
+	legend("topright", legend=c("a"), col=c(1), lty=c(1), pch=c(NA))
+})`
+	if len(f.Cmds) != 1 || f.Cmds[0] != want {
+		t.Errorf("expected command\n%s\ngot\n%q", want, f.Cmds)
+	}
+}
+
+func TestFigureNonFinite(t *testing.T) {
+	f := rgotest.NewFake()
+	f.Allow(".*")
+	fig := &Figure{Series: []Series{
+		{X: []float64{1, 2, math.NaN()}, Y: []float64{math.Inf(-1), 3, 4}},
+	}}
+	if err := fig.Render(f); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "plot(NA, xlim=c(1, 2), ylim=c(3, 4),"
+	if len(f.Cmds) != 1 || !strings.Contains(f.Cmds[0], want) {
+		t.Errorf("expected command containing %q, got %q", want, f.Cmds)
+	}
+}
+
+func TestFigureValidate(t *testing.T) {
+	bad := []*Figure{
+		{XLim: []float64{1}},
+		{YLim: []float64{0, math.Inf(1)}},
+		{Log: "z"},
+		{Series: []Series{{X: []float64{1}, Y: []float64{1, 2}}}},
+		{Series: []Series{{}}},
+		{Axes: []AxisSpec{{Side: 5}}},
+		{Axes: []AxisSpec{{Side: Left, At: []float64{1}, Labels: []string{"a", "b"}}}},
+		{Series: []Series{{X: []float64{1}, Y: []float64{1}}}, Legend: &LegendSpec{}},
+	}
+	for i, fig := range bad {
+		f := rgotest.NewFake()
+		if err := fig.Render(f); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+		if len(f.Cmds) != 0 {
+			t.Errorf("case %d: expected no commands, got %q", i, f.Cmds)
+		}
+	}
+}
+
+func TestFigureJSON(t *testing.T) {
+	fig := testFigure()
+	fig.Cfg = GraphCfg{}.WithLwd(2)
+	b, err := json.Marshal(fig)
+	if err != nil {
+		t.Fatalf("unable to encode figure: %v", err)
+	}
+	var got Figure
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("unable to decode figure: %v", err)
+	}
+	if !reflect.DeepEqual(&got, fig) {
+		t.Errorf("figure changed in round trip:\nwant %+v\ngot  %+v", fig, &got)
+	}
+}
+
+func TestFigureDefaultType(t *testing.T) {
+	f := rgotest.NewFake()
+	f.Allow(".*")
+	fig := &Figure{
+		Series: []Series{{Name: "a", X: []float64{1}, Y: []float64{2}}},
+		Legend: &LegendSpec{Pos: "topleft"},
+	}
+	if err := fig.Render(f); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, s := range []string{
+		`lines(c(1), c(2), type="p", col=1)`,
+		`legend("topleft", legend=c("a"), col=c(1), lty=c(NA), pch=c(1))`,
+	} {
+		if !strings.Contains(f.Cmds[0], s) {
+			t.Errorf("expected command to contain %q, got\n%s", s, f.Cmds[0])
+		}
+	}
+}
+
+func TestGraphCfgUnmarshalNames(t *testing.T) {
+	for _, name := range []string{`x=system("id"), y`, "", "1a", "if", "a b", "_x", "NA_character_", "..2"} {
+		var g GraphCfg
+		b, _ := json.Marshal([][2]string{{name, "1"}})
+		if err := json.Unmarshal(b, &g); err == nil {
+			t.Errorf("expected error decoding argument name %q", name)
+		}
+	}
+	var g GraphCfg
+	if err := json.Unmarshal([]byte(`[["names.arg", "1"], [".x", "2"]]`), &g); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,320 @@
+package rutil
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/uluyol/rgo/internal/rname"
+)
+
+// Raw is an R expression that is passed to R unchanged.
+type Raw string
+
+// GraphCfg holds optional arguments for R graphics functions.
+// Methods return a modified copy and leave the receiver unchanged.
+// Setting an argument that is already set replaces its value.
+//
+// An invalid argument (see With) records an error in the GraphCfg.
+// Once a GraphCfg has an error, further changes are ignored and
+// functions that are passed the GraphCfg return the error without
+// running any R code.
+type GraphCfg struct {
+	args []arg
+	err  error
+}
+
+type arg struct {
+	k string
+	v string // R expression
+}
+
+func (g GraphCfg) addKV(k, v string) GraphCfg {
+	if g.err != nil {
+		return g
+	}
+	args := make([]arg, 0, len(g.args)+1)
+	replaced := false
+	for _, a := range g.args {
+		if a.k == k {
+			a.v = v
+			replaced = true
+		}
+		args = append(args, a)
+	}
+	if !replaced {
+		args = append(args, arg{k, v})
+	}
+	return GraphCfg{args: args}
+}
+
+func (g GraphCfg) withErr(err error) GraphCfg {
+	if g.err == nil {
+		g.err = err
+	}
+	return g
+}
+
+// Err returns the first error recorded while building g.
+func (g GraphCfg) Err() error { return g.err }
+
+// cfgErr returns the first error recorded in cfgs.
+func cfgErr(cfgs ...GraphCfg) error {
+	for _, g := range cfgs {
+		if g.err != nil {
+			return g.err
+		}
+	}
+	return nil
+}
+
+// update returns g with the arguments of o added, replacing those
+// that are already set.
+func (g GraphCfg) update(o GraphCfg) GraphCfg {
+	if o.err != nil {
+		return g.withErr(o.err)
+	}
+	for _, a := range o.args {
+		g = g.addKV(a.k, a.v)
+	}
+	return g
+}
+
+// MarshalJSON encodes g as a list of [name, R expression] pairs.
+func (g GraphCfg) MarshalJSON() ([]byte, error) {
+	if g.err != nil {
+		return nil, g.err
+	}
+	pairs := make([][2]string, len(g.args))
+	for i, a := range g.args {
+		pairs[i] = [2]string{a.k, a.v}
+	}
+	return json.Marshal(pairs)
+}
+
+// UnmarshalJSON decodes arguments encoded by MarshalJSON. Argument
+// names must be syntactic R names, but values are R expressions that
+// are run when the arguments are used, so only decode arguments from
+// trusted sources.
+func (g *GraphCfg) UnmarshalJSON(data []byte) error {
+	var pairs [][2]string
+	if err := json.Unmarshal(data, &pairs); err != nil {
+		return err
+	}
+	g.args = nil
+	for _, p := range pairs {
+		if !rname.Valid(p[0]) {
+			return fmt.Errorf("invalid argument name %q", p[0])
+		}
+		*g = g.addKV(p[0], p[1])
+	}
+	return nil
+}
+
+// get returns the R expression k is set to.
+func (g GraphCfg) get(k string) (string, bool) {
+	for _, a := range g.args {
+		if a.k == k {
+			return a.v, true
+		}
+	}
+	return "", false
+}
+
+// split separates the arguments in keys from the rest.
+func (g GraphCfg) split(keys ...string) (in, out GraphCfg) {
+	in.err, out.err = g.err, g.err
+	for _, a := range g.args {
+		found := false
+		for _, k := range keys {
+			if a.k == k {
+				found = true
+				break
+			}
+		}
+		if found {
+			in.args = append(in.args, a)
+		} else {
+			out.args = append(out.args, a)
+		}
+	}
+	return in, out
+}
+
+func (g GraphCfg) params() string {
+	var s string
+	for _, a := range g.args {
+		s += ", " + a.k + "=" + a.v
+	}
+	return s
+}
+
+// rNum formats f as an R number.
+func rNum(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func rBool(b bool) string {
+	if b {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+func rVec(elems []string) string {
+	return "c(" + strings.Join(elems, ", ") + ")"
+}
+
+func rNums(v []float64) string {
+	s := make([]string, len(v))
+	for i := range v {
+		s[i] = rNum(v[i])
+	}
+	return rVec(s)
+}
+
+func rInts(v []int) string {
+	s := make([]string, len(v))
+	for i := range v {
+		s[i] = strconv.Itoa(v[i])
+	}
+	return rVec(s)
+}
+
+func rStrs(v []string) string {
+	s := make([]string, len(v))
+	for i := range v {
+		s[i] = strconv.Quote(v[i])
+	}
+	return rVec(s)
+}
+
+var rawType = reflect.TypeOf(Raw(""))
+
+// rValue formats v, which may be a Raw R expression, a string, bool,
+// integer or floating-point number, or a slice or array of them, as
+// an R expression.
+func rValue(v interface{}) (string, error) {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return "", errors.New("rutil: unsupported argument type <nil>")
+	}
+	if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		s := make([]string, rv.Len())
+		for i := range s {
+			e, err := rScalar(rv.Index(i))
+			if err != nil {
+				return "", err
+			}
+			s[i] = e
+		}
+		return rVec(s), nil
+	}
+	return rScalar(rv)
+}
+
+func rScalar(v reflect.Value) (string, error) {
+	if v.Type() == rawType {
+		return v.String(), nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return strconv.Quote(v.String()), nil
+	case reflect.Bool:
+		return rBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32:
+		// Format with 32-bit precision so that e.g. float32(0.1) is
+		// not written as 0.100000001490116.
+		if f := v.Float(); !math.IsNaN(f) && !math.IsInf(f, 0) {
+			return strconv.FormatFloat(f, 'g', -1, 32), nil
+		}
+		return rNum(v.Float()), nil
+	case reflect.Float64:
+		return rNum(v.Float()), nil
+	}
+	return "", fmt.Errorf("rutil: unsupported argument type %s", v.Type())
+}
+
+// With sets the argument k to v. v may be a Raw R expression, a
+// string, bool, integer or floating-point number, or a slice or
+// array of them. If k is not a syntactic R name or v has any other
+// type, the error is recorded in the returned GraphCfg and returned
+// by Err and by the functions that g is passed to.
+func (g GraphCfg) With(k string, v interface{}) GraphCfg {
+	if !rname.Valid(k) {
+		return g.withErr(fmt.Errorf("rutil: invalid argument name %q", k))
+	}
+	expr, err := rValue(v)
+	if err != nil {
+		return g.withErr(fmt.Errorf("%v for argument %s", err, k))
+	}
+	return g.addKV(k, expr)
+}
+
+// WithRaw sets the argument k to the R expression expr. Like With,
+// it records an error if k is not a syntactic R name.
+func (g GraphCfg) WithRaw(k, expr string) GraphCfg {
+	if !rname.Valid(k) {
+		return g.withErr(fmt.Errorf("rutil: invalid argument name %q", k))
+	}
+	return g.addKV(k, expr)
+}
+
+func (g GraphCfg) WithCol(color string) GraphCfg  { return g.With("col", color) }
+func (g GraphCfg) WithType(t string) GraphCfg     { return g.With("type", t) }
+func (g GraphCfg) WithMain(title string) GraphCfg { return g.With("main", title) }
+func (g GraphCfg) WithXLab(label string) GraphCfg { return g.With("xlab", label) }
+func (g GraphCfg) WithYLab(label string) GraphCfg { return g.With("ylab", label) }
+func (g GraphCfg) WithLwd(width float64) GraphCfg { return g.With("lwd", width) }
+func (g GraphCfg) WithLty(lty int) GraphCfg       { return g.With("lty", lty) }
+func (g GraphCfg) WithPch(pch int) GraphCfg       { return g.With("pch", pch) }
+func (g GraphCfg) WithCex(cex float64) GraphCfg   { return g.With("cex", cex) }
+func (g GraphCfg) WithAxes(show bool) GraphCfg    { return g.With("axes", show) }
+
+func (g GraphCfg) WithXLim(lo, hi float64) GraphCfg { return g.With("xlim", []float64{lo, hi}) }
+func (g GraphCfg) WithYLim(lo, hi float64) GraphCfg { return g.With("ylim", []float64{lo, hi}) }
+
+// WithLog sets which axes use a log scale: "x", "y" or "xy".
+func (g GraphCfg) WithLog(axes string) GraphCfg { return g.With("log", axes) }
+
+// WithLogX makes the x axis use a log scale. A log scale set on the
+// y axis is kept.
+func (g GraphCfg) WithLogX() GraphCfg { return g.addLog("x") }
+
+// WithLogY makes the y axis use a log scale. A log scale set on the
+// x axis is kept.
+func (g GraphCfg) WithLogY() GraphCfg { return g.addLog("y") }
+
+func (g GraphCfg) addLog(axis string) GraphCfg {
+	cur, _ := g.get("log")
+	cur, _ = strconv.Unquote(cur)
+	x := axis == "x" || strings.Contains(cur, "x")
+	y := axis == "y" || strings.Contains(cur, "y")
+	switch {
+	case x && y:
+		return g.WithLog("xy")
+	case x:
+		return g.WithLog("x")
+	}
+	return g.WithLog("y")
+}
+
+// WithLas sets the orientation of axis labels: 0 is parallel to the
+// axis, 1 horizontal, 2 perpendicular to the axis and 3 vertical.
+func (g GraphCfg) WithLas(las int) GraphCfg { return g.With("las", las) }
@@ -0,0 +1,106 @@
+package rutil
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/uluyol/rgo/rgotest"
+)
+
+func TestGraphCfgParams(t *testing.T) {
+	base := GraphCfg{}.WithCol("red")
+	testCases := []struct {
+		Cfg  GraphCfg
+		Want string
+	}{
+		{GraphCfg{}, ""},
+		{base, `, col="red"`},
+		{base.WithLwd(2).WithCex(0.8).WithPch(19), `, col="red", lwd=2, cex=0.8, pch=19`},
+		{GraphCfg{}.WithXLim(0, 10).WithLog("y").WithAxes(false), `, xlim=c(0, 10), log="y", axes=FALSE`},
+		{base.WithCol("blue"), `, col="blue"`},
+		{GraphCfg{}.WithMain(`say "hi"`), `, main="say \"hi\""`},
+		{GraphCfg{}.With("at", []int{1, 2}).With("lab", []string{"a", "b"}), `, at=c(1, 2), lab=c("a", "b")`},
+		{GraphCfg{}.With("ylim", []float64{math.Inf(-1), math.NaN()}), `, ylim=c(-Inf, NaN)`},
+		{GraphCfg{}.WithRaw("col", "rainbow(3)").With("bg", Raw("NA")), `, col=rainbow(3), bg=NA`},
+	}
+	for i, c := range testCases {
+		if got := c.Cfg.params(); got != c.Want {
+			t.Errorf("case %d: expected %q, got %q", i, c.Want, got)
+		}
+	}
+	if got := base.params(); got != `, col="red"` {
+		t.Errorf("base config was modified: %q", got)
+	}
+}
+
+func TestGraphCfgKinds(t *testing.T) {
+	type level int
+	testCases := []struct {
+		V    interface{}
+		Want string
+	}{
+		{int32(-3), "-3"},
+		{uint(7), "7"},
+		{uint8(255), "255"},
+		{float32(0.1), "0.1"},
+		{level(2), "2"},
+		{[]float32{0.5, 2}, "c(0.5, 2)"},
+		{[]uint16{1, 2}, "c(1, 2)"},
+		{[2]int{3, 4}, "c(3, 4)"},
+		{[]Raw{"NA", "1"}, "c(NA, 1)"},
+		{[]int{}, "c()"},
+	}
+	for i, c := range testCases {
+		g := GraphCfg{}.With("x", c.V)
+		if g.Err() != nil {
+			t.Errorf("case %d: unexpected error: %v", i, g.Err())
+		} else if got, want := g.params(), ", x="+c.Want; got != want {
+			t.Errorf("case %d: expected %q, got %q", i, want, got)
+		}
+	}
+}
+
+func TestGraphCfgErr(t *testing.T) {
+	testCases := []GraphCfg{
+		GraphCfg{}.With("x", struct{}{}),
+		GraphCfg{}.With("x", nil),
+		GraphCfg{}.With("x", map[string]int{}),
+		GraphCfg{}.With("a b", 1),
+		GraphCfg{}.With("..1", 1),
+		GraphCfg{}.WithRaw("x=1, y", "2"),
+		GraphCfg{}.With("x", []interface{}{1}).WithCol("red"),
+		GraphCfg{}.WithCol("red").update(GraphCfg{}.With("if", 1)),
+	}
+	for i, g := range testCases {
+		if g.Err() == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+		if _, err := g.MarshalJSON(); err == nil {
+			t.Errorf("case %d: expected error encoding", i)
+		}
+	}
+
+	f := rgotest.NewFake()
+	if err := Plot(f, []float64{1}, []float64{2}, testCases[0]); err == nil {
+		t.Errorf("expected error plotting with invalid GraphCfg")
+	}
+	if _, ok := f.Sent("go.x"); ok {
+		t.Errorf("sent data despite invalid GraphCfg")
+	}
+}
+
+func TestPlot(t *testing.T) {
+	f := rgotest.NewFake()
+	f.Expect(`^plot\(go.x, go.y, lwd=2\)$`)
+	if err := PlotX(f, []float64{3, 4}, GraphCfg{}.WithLwd(2)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	x, _ := f.Sent("go.x")
+	if !reflect.DeepEqual(x, []float64{1, 2}) {
+		t.Errorf("expected x to be [1 2], got %v", x)
+	}
+	if err := f.Unmet(); err != nil {
+		t.Error(err)
+	}
+}
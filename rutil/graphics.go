@@ -0,0 +1,192 @@
+package rutil
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/uluyol/rgo"
+)
+
+// Side is a side of a plot, as used by axis() and mtext().
+type Side int
+
+const (
+	Bottom Side = 1
+	Left   Side = 2
+	Top    Side = 3
+	Right  Side = 4
+)
+
+// sendMatrix sends m to R as a matrix with a row for each element
+// of m. All rows must have the same length.
+func sendMatrix(rc rgo.Executor, m [][]float64, name string) error {
+	mat, err := NewMatrix(m)
+	if err != nil {
+		return err
+	}
+	return mat.send(rc, name)
+}
+
+// sendList sends vals to R as a list of numeric vectors.
+func sendList(rc rgo.Executor, vals [][]float64, name string) error {
+	vars := make([]string, len(vals))
+	for i := range vals {
+		vars[i] = fmt.Sprintf("%s.%d", name, i)
+		if err := sendFloats(rc, vals[i], vars[i]); err != nil {
+			return err
+		}
+	}
+	return rc.Rf("%s <- lapply(list(%s), as.double)", name, strings.Join(vars, ", "))
+}
+
+// Points adds points to the current plot.
+func Points(rc rgo.Executor, x, y []float64, cfg GraphCfg) error {
+	return plotCommon(rc, "points", x, y, cfg)
+}
+
+// Polygon draws the polygon with vertices x and y.
+func Polygon(rc rgo.Executor, x, y []float64, cfg GraphCfg) error {
+	return plotCommon(rc, "polygon", x, y, cfg)
+}
+
+// Area shades the area between lo and hi.
+func Area(rc rgo.Executor, x, lo, hi []float64, cfg GraphCfg) error {
+	if err := cfg.Err(); err != nil {
+		return err
+	}
+	if err := sendFloats(rc, x, "go.x"); err != nil {
+		return err
+	}
+	if err := sendBounds(rc, lo, hi); err != nil {
+		return err
+	}
+	return rc.Rf("polygon(c(go.x, rev(go.x)), c(go.lo, rev(go.hi))%s)", cfg.params())
+}
+
+// Hist plots a histogram of x. The number of bins can be set using
+// the "breaks" argument.
+func Hist(rc rgo.Executor, x []float64, cfg GraphCfg) error {
+	if err := cfg.Err(); err != nil {
+		return err
+	}
+	if err := sendFloats(rc, x, "go.x"); err != nil {
+		return err
+	}
+	return rc.Rf("hist(go.x%s)", cfg.params())
+}
+
+// BarPlot draws a bar for each element of heights. names, if not
+// nil, labels the bars.
+func BarPlot(rc rgo.Executor, heights []float64, names []string, cfg GraphCfg) error {
+	if err := cfg.Err(); err != nil {
+		return err
+	}
+	if names != nil {
+		cfg = cfg.With("names.arg", names)
+	}
+	if err := sendFloats(rc, heights, "go.heights"); err != nil {
+		return err
+	}
+	return rc.Rf("barplot(as.double(go.heights)%s)", cfg.params())
+}
+
+// GroupedBarPlot draws bars for several series of values. heights[i][j]
+// is the value of series i in group j. Bars within a group are drawn
+// next to each other, or on top of each other if stacked is true.
+// groups and series, if not nil, label the groups and add a legend.
+func GroupedBarPlot(rc rgo.Executor, heights [][]float64, groups, series []string, stacked bool, cfg GraphCfg) error {
+	if err := cfg.Err(); err != nil {
+		return err
+	}
+	cfg = cfg.With("beside", !stacked)
+	if groups != nil {
+		cfg = cfg.With("names.arg", groups)
+	}
+	if series != nil {
+		cfg = cfg.With("legend.text", series)
+	}
+	if err := sendMatrix(rc, heights, "go.heights"); err != nil {
+		return err
+	}
+	return rc.Rf("barplot(go.heights%s)", cfg.params())
+}
+
+// BoxPlot draws a box for each group of values. names, if not nil,
+// labels the boxes.
+func BoxPlot(rc rgo.Executor, groups [][]float64, names []string, cfg GraphCfg) error {
+	if err := cfg.Err(); err != nil {
+		return err
+	}
+	if names != nil {
+		cfg = cfg.With("names", names)
+	}
+	if err := sendList(rc, groups, "go.groups"); err != nil {
+		return err
+	}
+	return rc.Rf("boxplot(go.groups%s)", cfg.params())
+}
+
+// ABLine adds the line y = a + b*x to the current plot.
+func ABLine(rc rgo.Executor, a, b float64, cfg GraphCfg) error {
+	if err := cfg.Err(); err != nil {
+		return err
+	}
+	return rc.Rf("abline(a=%s, b=%s%s)", rNum(a), rNum(b), cfg.params())
+}
+
+// HLine adds horizontal lines at each y to the current plot.
+func HLine(rc rgo.Executor, y []float64, cfg GraphCfg) error {
+	if err := cfg.Err(); err != nil {
+		return err
+	}
+	return rc.Rf("abline(h=%s%s)", rNums(y), cfg.params())
+}
+
+// VLine adds vertical lines at each x to the current plot.
+func VLine(rc rgo.Executor, x []float64, cfg GraphCfg) error {
+	if err := cfg.Err(); err != nil {
+		return err
+	}
+	return rc.Rf("abline(v=%s%s)", rNums(x), cfg.params())
+}
+
+// Text adds each label at the corresponding coordinates.
+func Text(rc rgo.Executor, x, y []float64, labels []string, cfg GraphCfg) error {
+	if err := cfg.Err(); err != nil {
+		return err
+	}
+	if err := sendXY(rc, x, y); err != nil {
+		return err
+	}
+	if err := rc.Send(labels, "go.labels"); err != nil {
+		return err
+	}
+	return rc.Rf("text(go.x, go.y, labels=go.labels%s)", cfg.params())
+}
+
+// Legend adds a legend at pos (e.g. "topright") to the current plot.
+// The symbols are taken from cfg, e.g. use With("col", colors) and
+// WithLty(1) for a legend of lines.
+func Legend(rc rgo.Executor, pos string, labels []string, cfg GraphCfg) error {
+	if err := cfg.Err(); err != nil {
+		return err
+	}
+	return rc.Rf("legend(%s, legend=%s%s)", strconv.Quote(pos), rStrs(labels), cfg.params())
+}
+
+// Axis adds an axis to side of the current plot. Ticks are placed at
+// at and labeled with labels. If at is nil, R picks the positions.
+// If labels is nil, the positions are used as labels.
+func Axis(rc rgo.Executor, side Side, at []float64, labels []string, cfg GraphCfg) error {
+	if err := cfg.Err(); err != nil {
+		return err
+	}
+	if at != nil {
+		cfg = cfg.With("at", at)
+	}
+	if labels != nil {
+		cfg = cfg.With("labels", labels)
+	}
+	return rc.Rf("axis(%d%s)", side, cfg.params())
+}
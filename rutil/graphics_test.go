@@ -0,0 +1,78 @@
+package rutil
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/uluyol/rgo/rgotest"
+)
+
+func TestGraphics(t *testing.T) {
+	f := rgotest.NewFake()
+	f.Allow(".*")
+	cfg := GraphCfg{}.WithCol("red")
+
+	Hist(f, []float64{1, 2, 2}, cfg.With("breaks", 10))
+	BarPlot(f, []float64{1, 2}, []string{"a", "b"}, cfg)
+	GroupedBarPlot(f, [][]float64{{1, 2}, {3, 4}}, []string{"g1", "g2"}, []string{"s1", "s2"}, true, GraphCfg{})
+	BoxPlot(f, [][]float64{{1, 2}, {3}}, nil, GraphCfg{})
+	ABLine(f, 0, 1.5, GraphCfg{}.WithLty(2))
+	HLine(f, []float64{1, 2}, GraphCfg{})
+	Text(f, []float64{1}, []float64{2}, []string{"x"}, GraphCfg{})
+	Legend(f, "topright", []string{"a", "b"}, GraphCfg{}.With("col", []string{"red", "blue"}).WithLty(1))
+	Axis(f, Left, []float64{1, 10}, []string{"1", "10"}, GraphCfg{}.With("las", 1))
+	Area(f, []float64{1, 2}, []float64{0, 0}, []float64{1, 1}, cfg)
+	if err := f.Error(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{
+		`hist(go.x, col="red", breaks=10)`,
+		`barplot(as.double(go.heights), col="red", names.arg=c("a", "b"))`,
+		`go.heights <- matrix(as.double(go.heights), nrow=2, byrow=TRUE)`,
+		`barplot(go.heights, beside=FALSE, names.arg=c("g1", "g2"), legend.text=c("s1", "s2"))`,
+		`go.groups <- lapply(list(go.groups.0, go.groups.1), as.double)`,
+		`boxplot(go.groups)`,
+		`abline(a=0, b=1.5, lty=2)`,
+		`abline(h=c(1, 2))`,
+		`text(go.x, go.y, labels=go.labels)`,
+		`legend("topright", legend=c("a", "b"), col=c("red", "blue"), lty=1)`,
+		`axis(2, las=1, at=c(1, 10), labels=c("1", "10"))`,
+		`polygon(c(go.x, rev(go.x)), c(go.lo, rev(go.hi)), col="red")`,
+	}
+	if !reflect.DeepEqual(f.Cmds, want) {
+		t.Errorf("expected commands\n%q\ngot\n%q", want, f.Cmds)
+	}
+	heights, _ := f.Sent("go.heights")
+	if !reflect.DeepEqual(heights, []float64{1, 2, 3, 4}) {
+		t.Errorf("expected flattened heights, got %v", heights)
+	}
+}
+
+func TestSendMatrixRagged(t *testing.T) {
+	f := rgotest.NewFake()
+	if err := sendMatrix(f, [][]float64{{1, 2}, {3}}, "m"); err == nil {
+		t.Errorf("expected error sending ragged matrix")
+	}
+}
+
+func TestGraphicsNonFinite(t *testing.T) {
+	f := rgotest.NewFake()
+	f.Allow(".*")
+	v := []float64{1, math.NaN(), math.Inf(-1)}
+	Points(f, v, v, GraphCfg{})
+	BarPlotErr(f, v, v, v, nil, GraphCfg{})
+	BoxPlot(f, [][]float64{v}, nil, GraphCfg{})
+	if err := f.Error(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, name := range []string{"go.x", "go.y", "go.heights", "go.lo", "go.hi", "go.groups.0"} {
+		var got []*float64
+		if err := f.Get(&got, name); err != nil {
+			t.Errorf("unable to encode %s: %v", name, err)
+		} else if len(got) != 3 || *got[0] != 1 || got[1] != nil || got[2] != nil {
+			t.Errorf("expected non-finite values of %s to be sent as null, got %v", name, got)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package rutil
+
+import (
+	"fmt"
+
+	"github.com/uluyol/rgo"
+)
+
+// HeatOpts configures Image and Heatmap.
+type HeatOpts struct {
+	// Palette is Viridis if not set.
+	Palette Palette
+	// Colors is the number of colors to use, by default 64.
+	Colors int
+	// Legend adds a color scale to the right of an Image. It is
+	// drawn using layout(), so it cannot be used within Panels.
+	// heatmap() uses the whole layout itself, so Heatmap does not
+	// support a legend and returns an error if Legend is set.
+	Legend bool
+	// Cluster reorders the rows and columns of a Heatmap by
+	// hierarchical clustering and draws the dendrograms.
+	Cluster bool
+	// Cfg holds additional arguments for image() or heatmap().
+	Cfg GraphCfg
+}
+
+func (o *HeatOpts) colors() string {
+	p, n := o.Palette, o.Colors
+	if p == "" {
+		p = Viridis
+	}
+	if n <= 0 {
+		n = 64
+	}
+	return p.colors(n)
+}
+
+const imageStr = `local({
+	m <- go.m
+	cols <- %[1]s
+	zlim <- range(m, finite=TRUE)%[2]s
+	image(seq_len(ncol(m)), seq_len(nrow(m)), t(m[nrow(m):1, , drop=FALSE]), col=cols, zlim=zlim%[3]s)
+	axis(1, at=seq_len(ncol(m)), labels=if (is.null(colnames(m))) seq_len(ncol(m)) else colnames(m))
+	axis(2, at=seq_len(nrow(m)), labels=rev(if (is.null(rownames(m))) seq_len(nrow(m)) else rownames(m)), las=1)
+	box()%[4]s
+})`
+
+const imageLegendSetupStr = `
+	op <- par(mar=par("mar"))
+	layout(matrix(1:2, nrow=1), widths=c(5, 1))`
+
+const imageLegendStr = `
+	par(mar=c(op$mar[1], 0.5, op$mar[3], 3))
+	z <- seq(zlim[1], zlim[2], length.out=length(cols))
+	image(1, z, t(z), col=cols, axes=FALSE, xlab="", ylab="")
+	axis(4, las=1)
+	box()
+	par(op)
+	layout(1)`
+
+// Image draws m as a grid of colored cells with the first row at the
+// top. Rows and columns are labeled with the names of m, if set.
+func Image(rc rgo.Executor, m *Matrix, opts HeatOpts) error {
+	if err := opts.Cfg.Err(); err != nil {
+		return err
+	}
+	if m.Rows == 0 || m.Cols == 0 {
+		return fmt.Errorf("image requires a non-empty matrix, got %d by %d", m.Rows, m.Cols)
+	}
+	if err := m.send(rc, "go.m"); err != nil {
+		return err
+	}
+	cfg := GraphCfg{}.WithAxes(false).WithXLab("").WithYLab("").update(opts.Cfg)
+	setup, legend := "", ""
+	if opts.Legend {
+		setup, legend = imageLegendSetupStr, imageLegendStr
+	}
+	return rc.Rf(imageStr, opts.colors(), setup, cfg.params(), legend)
+}
+
+// Heatmap draws m using heatmap(). Values are not rescaled unless cfg
+// sets "scale". Unless opts.Cluster is set, the rows and columns keep
+// their order.
+func Heatmap(rc rgo.Executor, m *Matrix, opts HeatOpts) error {
+	if err := opts.Cfg.Err(); err != nil {
+		return err
+	}
+	if opts.Legend {
+		return fmt.Errorf("heatmap does not support a legend, use Image instead")
+	}
+	if m.Rows < 2 || m.Cols < 2 {
+		return fmt.Errorf("heatmap requires at least 2 rows and columns, got %d by %d", m.Rows, m.Cols)
+	}
+	if err := m.send(rc, "go.m"); err != nil {
+		return err
+	}
+	cfg := GraphCfg{}.With("scale", "none").WithRaw("col", opts.colors())
+	if !opts.Cluster {
+		cfg = cfg.WithRaw("Rowv", "NA").WithRaw("Colv", "NA")
+	}
+	return rc.Rf("heatmap(go.m%s)", cfg.update(opts.Cfg).params())
+}
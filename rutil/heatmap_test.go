@@ -0,0 +1,113 @@
+package rutil
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/uluyol/rgo/rgotest"
+)
+
+func TestMatrix(t *testing.T) {
+	m, err := NewMatrix([][]float64{{1, 2, 3}, {4, 5, 6}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m.Rows != 2 || m.Cols != 3 || m.At(1, 0) != 4 {
+		t.Errorf("unexpected matrix %+v", m)
+	}
+	m.RowNames = []string{"a", "b"}
+
+	f := rgotest.NewFake()
+	f.Allow(".*")
+	if err := m.send(f, "m"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{`m <- matrix(as.double(m), nrow=2, byrow=TRUE, dimnames=list(c("a", "b"), NULL))`}
+	if !reflect.DeepEqual(f.Cmds, want) {
+		t.Errorf("expected commands %q, got %q", want, f.Cmds)
+	}
+
+	m.ColNames = []string{"x"}
+	if err := m.send(rgotest.NewFake(), "m"); err == nil {
+		t.Errorf("expected error for wrong number of column names")
+	}
+}
+
+func TestMatrixNonFinite(t *testing.T) {
+	m, _ := NewMatrix([][]float64{{1, math.NaN()}, {math.Inf(1), 4}})
+	f := rgotest.NewFake()
+	f.Allow(".*")
+	if err := m.send(f, "m"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got []*float64
+	if err := f.Get(&got, "m"); err != nil {
+		t.Fatalf("unable to encode matrix: %v", err)
+	}
+	if len(got) != 4 || got[1] != nil || got[2] != nil || *got[0] != 1 || *got[3] != 4 {
+		t.Errorf("expected non-finite values to be sent as null, got %v", got)
+	}
+}
+
+func TestImage(t *testing.T) {
+	m, _ := NewMatrix([][]float64{{1, 2}, {3, 4}})
+	f := rgotest.NewFake()
+	f.Allow(".*")
+	if err := Image(f, m, HeatOpts{Palette: Heat, Colors: 10, Legend: true, Cfg: GraphCfg{}.WithMain("Sweep")}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(f.Cmds) != 2 {
+		t.Fatalf("expected 2 commands, got %q", f.Cmds)
+	}
+	for _, s := range []string{
+		"cols <- (heat.colors)(10)",
+		`layout(matrix(1:2, nrow=1), widths=c(5, 1))`,
+		`col=cols, zlim=zlim, axes=FALSE, xlab="", ylab="", main="Sweep")`,
+		"axis(4, las=1)",
+	} {
+		if !strings.Contains(f.Cmds[1], s) {
+			t.Errorf("expected command to contain %q, got\n%s", s, f.Cmds[1])
+		}
+	}
+
+	for _, rows := range [][][]float64{nil, {{}}} {
+		empty, _ := NewMatrix(rows)
+		f := rgotest.NewFake()
+		if err := Image(f, empty, HeatOpts{}); err == nil {
+			t.Errorf("expected error for %d by %d matrix", empty.Rows, empty.Cols)
+		}
+		if len(f.Cmds) != 0 {
+			t.Errorf("expected no commands, got %q", f.Cmds)
+		}
+	}
+}
+
+func TestHeatmap(t *testing.T) {
+	m, _ := NewMatrix([][]float64{{1, 2}, {3, 4}})
+	f := rgotest.NewFake()
+	f.Allow(".*")
+	Heatmap(f, m, HeatOpts{})
+	Heatmap(f, m, HeatOpts{Cluster: true, Cfg: GraphCfg{}.With("scale", "row")})
+	if err := f.Error(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{
+		`go.m <- matrix(as.double(go.m), nrow=2, byrow=TRUE)`,
+		`heatmap(go.m, scale="none", col=` + Viridis.colors(64) + `, Rowv=NA, Colv=NA)`,
+		`go.m <- matrix(as.double(go.m), nrow=2, byrow=TRUE)`,
+		`heatmap(go.m, scale="row", col=` + Viridis.colors(64) + `)`,
+	}
+	if !reflect.DeepEqual(f.Cmds, want) {
+		t.Errorf("expected commands\n%q\ngot\n%q", want, f.Cmds)
+	}
+
+	small, _ := NewMatrix([][]float64{{1, 2}})
+	if err := Heatmap(rgotest.NewFake(), small, HeatOpts{}); err == nil {
+		t.Errorf("expected error for single row")
+	}
+	if err := Heatmap(rgotest.NewFake(), m, HeatOpts{Legend: true}); err == nil {
+		t.Errorf("expected error for legend")
+	}
+}
@@ -1,34 +1,56 @@
 package rutil
 
 import (
-	"strings"
+	"math"
 
 	"github.com/uluyol/rgo"
 )
 
-type GraphCfg struct {
-	v []string
+// finiteOrNA returns v if all of its values are finite. Otherwise, it
+// returns a copy with nil for NaN and infinite values, which cannot be
+// encoded as JSON. R reads nil as NA.
+func finiteOrNA(v []float64) interface{} {
+	var out []*float64
+	for i := range v {
+		if math.IsNaN(v[i]) || math.IsInf(v[i], 0) {
+			if out == nil {
+				out = make([]*float64, len(v))
+				for j := 0; j < i; j++ {
+					out[j] = &v[j]
+				}
+			}
+			continue
+		}
+		if out != nil {
+			out[i] = &v[i]
+		}
+	}
+	if out == nil {
+		return v
+	}
+	return out
 }
 
-func (g GraphCfg) addKV(k, v string) GraphCfg {
-	g.v = append(g.v, k+`="`+v+`"`)
-	return g
+// sendFloats sends v to R as name. NaN and infinite values are sent
+// as NA.
+func sendFloats(rc rgo.Executor, v []float64, name string) error {
+	return rc.Send(finiteOrNA(v), name)
 }
 
-func (g GraphCfg) params() string {
-	var s string
-	if len(g.v) > 0 {
-		s = ", " + strings.Join(g.v, ", ")
+func sendXY(rc rgo.Executor, x, y []float64) error {
+	if err := sendFloats(rc, x, "go.x"); err != nil {
+		return err
 	}
-	return s
+	return sendFloats(rc, y, "go.y")
 }
 
-func (g GraphCfg) WithCol(color string) GraphCfg { return g.addKV("col", color) }
-func (g GraphCfg) WithType(t string) GraphCfg    { return g.addKV("type", t) }
-
-func plotCommon(rc *rgo.Conn, funcName string, x, y []float64, g GraphCfg) error {
-	rc.Send(x, "go.x")
-	rc.Send(y, "go.y")
+func plotCommon(rc rgo.Executor, funcName string, x, y []float64, g GraphCfg) error {
+	if err := g.Err(); err != nil {
+		return err
+	}
+	if err := sendXY(rc, x, y); err != nil {
+		return err
+	}
 	return rc.Rf("%s(go.x, go.y%s)", funcName, g.params())
 }
 
@@ -40,18 +62,18 @@ func genRange(n int) []float64 {
 	return r
 }
 
-func Plot(rc *rgo.Conn, x, y []float64, cfg GraphCfg) error {
+func Plot(rc rgo.Executor, x, y []float64, cfg GraphCfg) error {
 	return plotCommon(rc, "plot", x, y, cfg)
 }
 
-func Lines(rc *rgo.Conn, x, y []float64, cfg GraphCfg) error {
+func Lines(rc rgo.Executor, x, y []float64, cfg GraphCfg) error {
 	return plotCommon(rc, "lines", x, y, cfg)
 }
 
-func PlotX(rc *rgo.Conn, x []float64, cfg GraphCfg) error {
+func PlotX(rc rgo.Executor, x []float64, cfg GraphCfg) error {
 	return Plot(rc, genRange(len(x)), x, cfg)
 }
 
-func LinesX(rc *rgo.Conn, x []float64, cfg GraphCfg) error {
+func LinesX(rc rgo.Executor, x []float64, cfg GraphCfg) error {
 	return Lines(rc, genRange(len(x)), x, cfg)
 }
@@ -0,0 +1,112 @@
+package rutil
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/uluyol/rgo"
+	"github.com/uluyol/rgo/dataframe"
+)
+
+// Trellis selects the columns of a data frame for a lattice plot.
+//
+// Lattice plots are printed on the current device, so the caller
+// must open one first, e.g. using rgo.Conn.Device or DrawTo.
+// Otherwise, R would open its default device, which writes
+// Rplots.pdf when R is not interactive, so the plot functions return
+// an error instead.
+type Trellis struct {
+	X string
+	// Y is not used by DensityPlot and Histogram.
+	Y string
+	// By are the columns to condition on. A panel is drawn for each
+	// combination of their values.
+	By []string
+	// Group, if set, draws a separate series with a key for each
+	// value of the column within every panel.
+	Group string
+	// Cfg holds additional arguments for the lattice function,
+	// e.g. layout or main.
+	Cfg GraphCfg
+}
+
+// rName quotes a column name for use in an R formula.
+func rName(col string) string {
+	return "`" + strings.Replace(strings.Replace(col, `\`, `\\`, -1), "`", "\\`", -1) + "`"
+}
+
+// formula returns the formula for t. If y is false, the formula has
+// no left-hand side.
+func (t *Trellis) formula(y bool) string {
+	f := "~ " + rName(t.X)
+	if y {
+		f = rName(t.Y) + " " + f
+	}
+	if len(t.By) > 0 {
+		by := make([]string, len(t.By))
+		for i, c := range t.By {
+			by[i] = rName(c)
+		}
+		f += " | " + strings.Join(by, " + ")
+	}
+	return f
+}
+
+func trellis(rc rgo.Executor, df dataframe.DataFrame, fn string, t Trellis, y bool) error {
+	if err := t.Cfg.Err(); err != nil {
+		return err
+	}
+	if t.X == "" {
+		return fmt.Errorf("%s requires an x column", fn)
+	}
+	cols := append([]string{t.X}, t.By...)
+	switch {
+	case y && t.Y == "":
+		return fmt.Errorf("%s requires a y column", fn)
+	case y:
+		cols = append(cols, t.Y)
+	case t.Y != "":
+		return fmt.Errorf("%s does not use a y column", fn)
+	}
+	cfg := GraphCfg{}
+	if t.Group != "" {
+		cols = append(cols, t.Group)
+		cfg = cfg.WithRaw("groups", rName(t.Group)).With("auto.key", true)
+	}
+	if err := checkCols(df, cols...); err != nil {
+		return err
+	}
+	if err := rc.SendDF(df, "go.df"); err != nil {
+		return err
+	}
+	cfg = cfg.update(t.Cfg)
+	return rc.Rf(latticeStr, fn, t.formula(y), cfg.params())
+}
+
+// latticeStr prints a lattice plot if a device is open. Device 1 is
+// the null device, which dev.cur() returns if none is.
+const latticeStr = `if (dev.cur() == 1) stop("no graphics device is open") else print(lattice::%s(%s, data=go.df%s))`
+
+// XYPlot draws a scatterplot of t.Y against t.X using xyplot(). Like
+// the other lattice plots, df is sent to R as go.df and the plot is
+// printed on the current device, which must already be open.
+func XYPlot(rc rgo.Executor, df dataframe.DataFrame, t Trellis) error {
+	return trellis(rc, df, "xyplot", t, true)
+}
+
+// BWPlot draws box plots of t.Y for each value of t.X using
+// bwplot(). Either column may be the categorical one.
+func BWPlot(rc rgo.Executor, df dataframe.DataFrame, t Trellis) error {
+	return trellis(rc, df, "bwplot", t, true)
+}
+
+// DensityPlot draws kernel density estimates of t.X using
+// densityplot().
+func DensityPlot(rc rgo.Executor, df dataframe.DataFrame, t Trellis) error {
+	return trellis(rc, df, "densityplot", t, false)
+}
+
+// Histogram draws histograms of t.X using histogram().
+func Histogram(rc rgo.Executor, df dataframe.DataFrame, t Trellis) error {
+	return trellis(rc, df, "histogram", t, false)
+}
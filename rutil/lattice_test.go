@@ -0,0 +1,58 @@
+package rutil
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/uluyol/rgo/dataframe"
+	"github.com/uluyol/rgo/rgotest"
+)
+
+func TestLattice(t *testing.T) {
+	df := dataframe.New("size", "latency (ms)", "workload", "config")
+	df.AppendURow(1.0, 2.0, "a", "x")
+	df.AppendURow(2.0, 3.0, "b", "y")
+
+	f := rgotest.NewFake()
+	f.Allow(".*")
+	XYPlot(f, df, Trellis{X: "size", Y: "latency (ms)", By: []string{"workload"}, Group: "config"})
+	BWPlot(f, df, Trellis{X: "config", Y: "latency (ms)", Cfg: GraphCfg{}.With("layout", []int{2, 1})})
+	DensityPlot(f, df, Trellis{X: "latency (ms)", By: []string{"workload", "config"}})
+	Histogram(f, df, Trellis{X: "size", Group: "config", Cfg: GraphCfg{}.With("auto.key", false)})
+	if err := f.Error(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	check := `if (dev.cur() == 1) stop("no graphics device is open") else `
+	want := []string{
+		check + "print(lattice::xyplot(`latency (ms)` ~ `size` | `workload`, data=go.df, groups=`config`, auto.key=TRUE))",
+		check + "print(lattice::bwplot(`latency (ms)` ~ `config`, data=go.df, layout=c(2, 1)))",
+		check + "print(lattice::densityplot(~ `latency (ms)` | `workload` + `config`, data=go.df))",
+		check + "print(lattice::histogram(~ `size`, data=go.df, groups=`config`, auto.key=FALSE))",
+	}
+	if !reflect.DeepEqual(f.Cmds, want) {
+		t.Errorf("expected commands\n%q\ngot\n%q", want, f.Cmds)
+	}
+	if sent, _ := f.Sent("go.df"); sent != df {
+		t.Errorf("expected data frame to be sent as go.df")
+	}
+
+	bad := []func(*rgotest.Fake) error{
+		func(f *rgotest.Fake) error { return XYPlot(f, df, Trellis{X: "size"}) },
+		func(f *rgotest.Fake) error { return Histogram(f, df, Trellis{X: "size", Y: "config"}) },
+		func(f *rgotest.Fake) error { return DensityPlot(f, df, Trellis{}) },
+		func(f *rgotest.Fake) error { return XYPlot(f, df, Trellis{X: "size", Y: "nope"}) },
+	}
+	for i, fn := range bad {
+		f := rgotest.NewFake()
+		if err := fn(f); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+		if len(f.Cmds) != 0 {
+			t.Errorf("case %d: expected no commands, got %q", i, f.Cmds)
+		}
+	}
+
+	if got := rName("a`b"); got != "`a\\`b`" {
+		t.Errorf("unexpected quoted name %s", got)
+	}
+}
@@ -0,0 +1,332 @@
+package rutil
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/uluyol/rgo"
+)
+
+// Layout arranges several panels in a single figure.
+type Layout struct {
+	// Rows and Cols set up a grid of panels that is filled row by
+	// row, or column by column if ByCol is set.
+	Rows, Cols int
+	ByCol      bool
+
+	// Matrix, if set, is used instead of Rows and Cols and is passed
+	// to layout(). Matrix[i][j] is the panel (counting from 1) that
+	// covers cell j of row i, or 0 to leave the cell empty. Widths
+	// and Heights optionally give the relative sizes of the columns
+	// and rows.
+	Matrix          [][]int
+	Widths, Heights []float64
+
+	// Titles are drawn above each panel.
+	Titles []string
+
+	// Title, XLabel and YLabel are shared by all panels and drawn
+	// in the outer margins.
+	Title, XLabel, YLabel string
+
+	// ShareX and ShareY make the panels share their x or y axis,
+	// which is then only drawn by the panels along the bottom or
+	// left edge of the layout. Figures gives the panels common
+	// limits. Panels drawn by Panels must use the same limits
+	// themselves, e.g. WithXLim.
+	ShareX, ShareY bool
+
+	// Legend is drawn in the outer margin below the panels.
+	Legend *OuterLegend
+
+	// OuterMargin, if set, is the size of the bottom, left, top and
+	// right outer margins in lines. By default, there is room for
+	// the shared labels and legend.
+	OuterMargin []float64
+
+	// Cfg holds additional arguments for par(), e.g. mar.
+	Cfg GraphCfg
+}
+
+// OuterLegend is a legend shared by all panels of a Layout.
+type OuterLegend struct {
+	Labels []string
+	// Cfg holds the legend symbols, as for Legend.
+	Cfg GraphCfg
+}
+
+// cells returns the number of panels l has room for.
+func (l *Layout) cells() (int, error) {
+	if l.Matrix == nil {
+		if l.Rows <= 0 || l.Cols <= 0 {
+			return 0, fmt.Errorf("invalid grid of %d by %d panels", l.Rows, l.Cols)
+		}
+		return l.Rows * l.Cols, nil
+	}
+	if len(l.Matrix) == 0 || len(l.Matrix[0]) == 0 {
+		return 0, fmt.Errorf("layout matrix is empty")
+	}
+	seen := make(map[int]bool)
+	max := 0
+	for i, row := range l.Matrix {
+		if len(row) != len(l.Matrix[0]) {
+			return 0, fmt.Errorf("layout row %d has %d columns, expected %d", i, len(row), len(l.Matrix[0]))
+		}
+		for _, p := range row {
+			if p < 0 {
+				return 0, fmt.Errorf("invalid panel number %d", p)
+			}
+			seen[p] = true
+			if p > max {
+				max = p
+			}
+		}
+	}
+	for p := 1; p <= max; p++ {
+		if !seen[p] {
+			return 0, fmt.Errorf("layout has no cell for panel %d", p)
+		}
+	}
+	if l.Widths != nil && len(l.Widths) != len(l.Matrix[0]) {
+		return 0, fmt.Errorf("got %d widths for %d columns", len(l.Widths), len(l.Matrix[0]))
+	}
+	if l.Heights != nil && len(l.Heights) != len(l.Matrix) {
+		return 0, fmt.Errorf("got %d heights for %d rows", len(l.Heights), len(l.Matrix))
+	}
+	return max, nil
+}
+
+func (l *Layout) oma() []float64 {
+	if l.OuterMargin != nil {
+		return l.OuterMargin
+	}
+	oma := make([]float64, 4)
+	if l.XLabel != "" {
+		oma[0] += 2
+	}
+	if l.Legend != nil {
+		oma[0] += 2
+	}
+	if l.YLabel != "" {
+		oma[1] += 2
+	}
+	if l.Title != "" {
+		oma[2] += 2
+	}
+	return oma
+}
+
+// axisEdges reports for each of the first n panels whether it is on
+// the bottom edge of the layout, i.e. one of its cells has no drawn
+// panel below it, and whether it is on the left edge.
+func (l *Layout) axisEdges(n int) (bottom, left []bool) {
+	m := l.Matrix
+	if m == nil {
+		m = make([][]int, l.Rows)
+		for i := range m {
+			m[i] = make([]int, l.Cols)
+			for j := range m[i] {
+				if l.ByCol {
+					m[i][j] = j*l.Rows + i + 1
+				} else {
+					m[i][j] = i*l.Cols + j + 1
+				}
+			}
+		}
+	}
+	drawn := func(i, j int) bool {
+		return i < len(m) && j >= 0 && m[i][j] > 0 && m[i][j] <= n
+	}
+	bottom, left = make([]bool, n), make([]bool, n)
+	for i, row := range m {
+		for j, p := range row {
+			if !drawn(i, j) {
+				continue
+			}
+			if !drawn(i+1, j) {
+				bottom[p-1] = true
+			}
+			if !drawn(i, j-1) {
+				left[p-1] = true
+			}
+		}
+	}
+	return bottom, left
+}
+
+// Panels draws a figure with a panel for each function in panels,
+// arranged according to l. Each function draws its panel using e.g.
+// Plot and Lines.
+//
+// The graphical parameters and layout are restored once all panels
+// have been drawn, or if a panel function returns an error. An error
+// in R leaves rc unable to run further commands, so in that case
+// they are not restored.
+func Panels(rc rgo.Executor, l Layout, panels ...func() error) error {
+	if err := l.Cfg.Err(); err != nil {
+		return err
+	}
+	if l.Legend != nil {
+		if err := l.Legend.Cfg.Err(); err != nil {
+			return err
+		}
+	}
+	n, err := l.cells()
+	if err != nil {
+		return err
+	}
+	if len(panels) > n {
+		return fmt.Errorf("layout has room for %d panels, got %d", n, len(panels))
+	}
+	if len(l.Titles) > len(panels) {
+		return fmt.Errorf("got %d titles for %d panels", len(l.Titles), len(panels))
+	}
+	if len(l.OuterMargin) != 0 && len(l.OuterMargin) != 4 {
+		return fmt.Errorf("outer margin must have 4 values, got %d", len(l.OuterMargin))
+	}
+
+	if err := rc.R("go.par <- par(no.readonly=TRUE)"); err != nil {
+		return err
+	}
+	err = drawPanels(rc, &l, panels)
+	if rerr := rc.R(restoreParStr); err == nil {
+		err = rerr
+	}
+	return err
+}
+
+// restoreParStr undoes the layout and restores the graphical
+// parameters saved by Panels.
+const restoreParStr = `layout(1)
+par(go.par)`
+
+func drawPanels(rc rgo.Executor, l *Layout, panels []func() error) error {
+	par := GraphCfg{}
+	if l.Matrix == nil {
+		grid := "mfrow"
+		if l.ByCol {
+			grid = "mfcol"
+		}
+		par = par.With(grid, []int{l.Rows, l.Cols})
+	}
+	par = par.With("oma", l.oma()).update(l.Cfg)
+	if err := rc.Rf("par(%s)", strings.TrimPrefix(par.params(), ", ")); err != nil {
+		return err
+	}
+	if l.Matrix != nil {
+		var cells []int
+		for _, row := range l.Matrix {
+			cells = append(cells, row...)
+		}
+		var sizes GraphCfg
+		if l.Widths != nil {
+			sizes = sizes.With("widths", l.Widths)
+		}
+		if l.Heights != nil {
+			sizes = sizes.With("heights", l.Heights)
+		}
+		err := rc.Rf("layout(matrix(%s, nrow=%d, byrow=TRUE)%s)", rInts(cells), len(l.Matrix), sizes.params())
+		if err != nil {
+			return err
+		}
+	}
+
+	var bottom, left []bool
+	if l.ShareX || l.ShareY {
+		bottom, left = l.axisEdges(len(panels))
+	}
+	for i, draw := range panels {
+		if l.ShareX || l.ShareY {
+			var axes GraphCfg
+			if l.ShareX {
+				axes = axes.With("xaxt", axisType(bottom[i]))
+			}
+			if l.ShareY {
+				axes = axes.With("yaxt", axisType(left[i]))
+			}
+			if err := rc.Rf("par(%s)", strings.TrimPrefix(axes.params(), ", ")); err != nil {
+				return err
+			}
+		}
+		if err := draw(); err != nil {
+			return err
+		}
+		if i < len(l.Titles) && l.Titles[i] != "" {
+			if err := rc.Rf("title(main=%s)", strconv.Quote(l.Titles[i])); err != nil {
+				return err
+			}
+		}
+	}
+
+	outer := []struct {
+		text string
+		side Side
+		args string
+	}{
+		{l.XLabel, Bottom, ""},
+		{l.YLabel, Left, ""},
+		{l.Title, Top, ", font=2, cex=1.2"},
+	}
+	for _, o := range outer {
+		if o.text == "" {
+			continue
+		}
+		err := rc.Rf("mtext(%s, side=%d, outer=TRUE, line=0.5%s)", strconv.Quote(o.text), o.side, o.args)
+		if err != nil {
+			return err
+		}
+	}
+	if l.Legend != nil {
+		if err := rc.R(outerLegendStr); err != nil {
+			return err
+		}
+		cfg := GraphCfg{}.With("horiz", true).With("bty", "n").With("xpd", true).update(l.Legend.Cfg)
+		if err := Legend(rc, "bottom", l.Legend.Labels, cfg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// axisType returns the value of par("xaxt") or par("yaxt") that
+// shows an axis if show is set and hides it otherwise.
+func axisType(show bool) string {
+	if show {
+		return "s"
+	}
+	return "n"
+}
+
+// Figures draws each figure in a panel of l as Panels does. If
+// l.ShareX or l.ShareY is set, the limits of that axis are replaced
+// in every figure by a range that covers the data and limits of all
+// figures.
+func Figures(rc rgo.Executor, l Layout, figs ...*Figure) error {
+	var xs, ys [][]float64
+	for _, f := range figs {
+		if err := f.Validate(); err != nil {
+			return err
+		}
+		x, y := f.data()
+		xs = append(append(xs, x...), f.XLim)
+		ys = append(append(ys, y...), f.YLim)
+	}
+	panels := make([]func() error, len(figs))
+	for i, f := range figs {
+		shared := *f
+		if l.ShareX {
+			shared.XLim = dataRange(xs...)
+		}
+		if l.ShareY {
+			shared.YLim = dataRange(ys...)
+		}
+		panels[i] = func() error { return rc.R(shared.code()) }
+	}
+	return Panels(rc, l, panels...)
+}
+
+// outerLegendStr sets up a plot covering the whole device so that a
+// legend can be drawn in the outer margins.
+const outerLegendStr = `par(fig=c(0, 1, 0, 1), oma=c(0, 0, 0, 0), mar=c(0, 0, 0, 0), new=TRUE)
+plot.new()`
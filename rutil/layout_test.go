@@ -0,0 +1,175 @@
+package rutil
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/uluyol/rgo/rgotest"
+)
+
+func TestPanels(t *testing.T) {
+	f := rgotest.NewFake()
+	f.Allow(".*")
+	panel := func(y float64) func() error {
+		return func() error { return PlotX(f, []float64{y}, GraphCfg{}) }
+	}
+	l := Layout{
+		Rows:   1,
+		Cols:   2,
+		Titles: []string{"a", "b"},
+		XLabel: "Time",
+		Title:  "Workloads",
+		Legend: &OuterLegend{Labels: []string{"x"}, Cfg: GraphCfg{}.WithLty(1)},
+		Cfg:    GraphCfg{}.With("mar", []float64{2, 2, 1, 1}),
+	}
+	if err := Panels(f, l, panel(1), panel(2)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{
+		`go.par <- par(no.readonly=TRUE)`,
+		`par(mfrow=c(1, 2), oma=c(4, 0, 2, 0), mar=c(2, 2, 1, 1))`,
+		`plot(go.x, go.y)`,
+		`title(main="a")`,
+		`plot(go.x, go.y)`,
+		`title(main="b")`,
+		`mtext("Time", side=1, outer=TRUE, line=0.5)`,
+		`mtext("Workloads", side=3, outer=TRUE, line=0.5, font=2, cex=1.2)`,
+		outerLegendStr,
+		`legend("bottom", legend=c("x"), horiz=TRUE, bty="n", xpd=TRUE, lty=1)`,
+		restoreParStr,
+	}
+	if !reflect.DeepEqual(f.Cmds, want) {
+		t.Errorf("expected commands\n%q\ngot\n%q", want, f.Cmds)
+	}
+}
+
+func TestPanelsMatrix(t *testing.T) {
+	f := rgotest.NewFake()
+	f.Allow(".*")
+	l := Layout{
+		Matrix:      [][]int{{1, 1}, {2, 3}},
+		Heights:     []float64{2, 1},
+		OuterMargin: []float64{0, 0, 0, 0},
+	}
+	if err := Panels(f, l); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{
+		`go.par <- par(no.readonly=TRUE)`,
+		`par(oma=c(0, 0, 0, 0))`,
+		`layout(matrix(c(1, 1, 2, 3), nrow=2, byrow=TRUE), heights=c(2, 1))`,
+		restoreParStr,
+	}
+	if !reflect.DeepEqual(f.Cmds, want) {
+		t.Errorf("expected commands\n%q\ngot\n%q", want, f.Cmds)
+	}
+
+	bad := []Layout{
+		{},
+		{Rows: 1, Cols: 1, Titles: []string{"a"}},
+		{Matrix: [][]int{{1, 3}}},
+		{Matrix: [][]int{}, Widths: []float64{1}},
+		{Matrix: [][]int{{}}},
+		{Matrix: [][]int{{1, 2}, {1}}},
+		{Matrix: [][]int{{1}}, Widths: []float64{1, 2}},
+		{Rows: 1, Cols: 1, OuterMargin: []float64{1}},
+	}
+	for i, l := range bad {
+		f := rgotest.NewFake()
+		if err := Panels(f, l); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+		if len(f.Cmds) != 0 {
+			t.Errorf("case %d: expected no commands, got %q", i, f.Cmds)
+		}
+	}
+}
+
+func TestPanelsRestore(t *testing.T) {
+	f := rgotest.NewFake()
+	f.Allow(".*")
+	l := Layout{Rows: 2, Cols: 1, Legend: &OuterLegend{Labels: []string{"x"}}}
+	if err := Panels(f, l); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{
+		`go.par <- par(no.readonly=TRUE)`,
+		`par(mfrow=c(2, 1), oma=c(2, 0, 0, 0))`,
+		outerLegendStr,
+		`legend("bottom", legend=c("x"), horiz=TRUE, bty="n", xpd=TRUE)`,
+		restoreParStr,
+	}
+	if !reflect.DeepEqual(f.Cmds, want) {
+		t.Errorf("expected commands\n%q\ngot\n%q", want, f.Cmds)
+	}
+
+	f = rgotest.NewFake()
+	f.Allow(".*")
+	drawErr := errors.New("no data")
+	err := Panels(f, Layout{Rows: 1, Cols: 2, Titles: []string{"a"}}, func() error { return drawErr })
+	if err != drawErr {
+		t.Errorf("expected panel error, got %v", err)
+	}
+	if last := f.Cmds[len(f.Cmds)-1]; last != restoreParStr {
+		t.Errorf("expected parameters to be restored after error, got %q", f.Cmds)
+	}
+}
+
+func TestPanelsShared(t *testing.T) {
+	f := rgotest.NewFake()
+	f.Allow(".*")
+	l := Layout{Rows: 2, Cols: 2, ShareX: true, ShareY: true, OuterMargin: []float64{0, 0, 0, 0}}
+	noop := func() error { return nil }
+	if err := Panels(f, l, noop, noop, noop); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{
+		`go.par <- par(no.readonly=TRUE)`,
+		`par(mfrow=c(2, 2), oma=c(0, 0, 0, 0))`,
+		`par(xaxt="n", yaxt="s")`,
+		`par(xaxt="s", yaxt="n")`,
+		`par(xaxt="s", yaxt="s")`,
+		restoreParStr,
+	}
+	if !reflect.DeepEqual(f.Cmds, want) {
+		t.Errorf("expected commands\n%q\ngot\n%q", want, f.Cmds)
+	}
+
+	l = Layout{Matrix: [][]int{{1, 2}, {1, 3}}, ShareX: true}
+	bottom, left := l.axisEdges(3)
+	if !reflect.DeepEqual(bottom, []bool{true, false, true}) || !reflect.DeepEqual(left, []bool{true, false, false}) {
+		t.Errorf("unexpected axis edges %v and %v", bottom, left)
+	}
+}
+
+func TestFigures(t *testing.T) {
+	f := rgotest.NewFake()
+	f.Allow(".*")
+	figs := []*Figure{
+		{Series: []Series{{X: []float64{0, 1}, Y: []float64{1, 2}}}},
+		{Series: []Series{{X: []float64{2, 5}, Y: []float64{10, 20}}}},
+	}
+	l := Layout{Rows: 1, Cols: 2, ShareX: true}
+	if err := Figures(f, l, figs...); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var plots []string
+	for _, cmd := range f.Cmds {
+		if strings.HasPrefix(cmd, "local(") {
+			plots = append(plots, cmd)
+		}
+	}
+	if len(plots) != 2 {
+		t.Fatalf("expected 2 figures, got %q", f.Cmds)
+	}
+	for i, want := range []string{"ylim=c(1, 2)", "ylim=c(10, 20)"} {
+		if !strings.Contains(plots[i], "xlim=c(0, 5), "+want) {
+			t.Errorf("figure %d: expected shared xlim and %s, got %q", i, want, plots[i])
+		}
+	}
+	if figs[0].XLim != nil {
+		t.Errorf("figure was modified: %v", figs[0].XLim)
+	}
+}
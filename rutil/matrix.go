@@ -0,0 +1,72 @@
+package rutil
+
+import (
+	"fmt"
+
+	"github.com/uluyol/rgo"
+)
+
+// Matrix is a matrix of values stored row by row. RowNames and
+// ColNames are optional.
+type Matrix struct {
+	Rows, Cols int
+	// Data holds the element in row i and column j at i*Cols+j.
+	// NaN and infinite elements are sent to R as NA, e.g. for
+	// missing cells.
+	Data     []float64
+	RowNames []string
+	ColNames []string
+}
+
+// NewMatrix creates a Matrix from rows, which must all have the same
+// length.
+func NewMatrix(rows [][]float64) (*Matrix, error) {
+	m := &Matrix{Rows: len(rows)}
+	if len(rows) > 0 {
+		m.Cols = len(rows[0])
+	}
+	for i, row := range rows {
+		if len(row) != m.Cols {
+			return nil, fmt.Errorf("row %d has %d columns, expected %d", i, len(row), m.Cols)
+		}
+		m.Data = append(m.Data, row...)
+	}
+	return m, nil
+}
+
+// At returns the element in row i and column j.
+func (m *Matrix) At(i, j int) float64 { return m.Data[i*m.Cols+j] }
+
+func (m *Matrix) validate() error {
+	if len(m.Data) != m.Rows*m.Cols {
+		return fmt.Errorf("%d by %d matrix has %d elements", m.Rows, m.Cols, len(m.Data))
+	}
+	if m.RowNames != nil && len(m.RowNames) != m.Rows {
+		return fmt.Errorf("got %d row names for %d rows", len(m.RowNames), m.Rows)
+	}
+	if m.ColNames != nil && len(m.ColNames) != m.Cols {
+		return fmt.Errorf("got %d column names for %d columns", len(m.ColNames), m.Cols)
+	}
+	return nil
+}
+
+// send sends m to R as a matrix called name.
+func (m *Matrix) send(rc rgo.Executor, name string) error {
+	if err := m.validate(); err != nil {
+		return err
+	}
+	if err := sendFloats(rc, m.Data, name); err != nil {
+		return err
+	}
+	var dimnames string
+	if m.RowNames != nil || m.ColNames != nil {
+		names := func(n []string) string {
+			if n == nil {
+				return "NULL"
+			}
+			return rStrs(n)
+		}
+		dimnames = fmt.Sprintf(", dimnames=list(%s, %s)", names(m.RowNames), names(m.ColNames))
+	}
+	return rc.Rf("%s <- matrix(as.double(%s), nrow=%d, byrow=TRUE%s)", name, name, m.Rows, dimnames)
+}
@@ -0,0 +1,92 @@
+package rutil
+
+import (
+	"fmt"
+
+	"github.com/uluyol/rgo"
+	"github.com/uluyol/rgo/dataframe"
+)
+
+// PairsOpts configures PairsWith.
+type PairsOpts struct {
+	// Cor replaces the scatterplots above the diagonal with the
+	// correlation coefficient of each pair of columns.
+	Cor bool
+	// Hist draws a histogram of each column on the diagonal.
+	Hist bool
+	// Cfg holds additional arguments for pairs().
+	Cfg GraphCfg
+}
+
+const panelCorStr = `
+	panel.cor <- function(x, y, ...) {
+		usr <- par("usr")
+		on.exit(par(usr=usr))
+		par(usr=c(0, 1, 0, 1))
+		r <- suppressWarnings(cor(x, y, use="complete.obs"))
+		if (is.na(r)) {
+			text(0.5, 0.5, "NA")
+		} else {
+			text(0.5, 0.5, formatC(r, digits=2, format="f"), cex=1 + abs(r))
+		}
+	}`
+
+const panelHistStr = `
+	panel.hist <- function(x, ...) {
+		usr <- par("usr")
+		on.exit(par(usr=usr))
+		par(usr=c(usr[1:2], 0, 1.5))
+		h <- hist(x, plot=FALSE)
+		rect(h$breaks[-length(h$breaks)], 0, h$breaks[-1], h$counts / max(h$counts), col="gray")
+	}`
+
+// Pairs draws a scatterplot of every pair of the columns cols of df.
+// If no columns are given, all columns of df are used. The columns
+// must be numeric. If the correlation of a pair of columns is
+// undefined, e.g. because one is constant, PairsWith shows it as NA.
+func Pairs(rc rgo.Executor, df dataframe.DataFrame, cols ...string) error {
+	return PairsWith(rc, df, PairsOpts{}, cols...)
+}
+
+// PairsWith is like Pairs but allows correlation coefficients and
+// histograms to be added.
+func PairsWith(rc rgo.Executor, df dataframe.DataFrame, opts PairsOpts, cols ...string) error {
+	if err := opts.Cfg.Err(); err != nil {
+		return err
+	}
+	sel := "go.df"
+	if len(cols) > 0 {
+		if err := checkCols(df, cols...); err != nil {
+			return err
+		}
+		sel = fmt.Sprintf("go.df[, %s, drop=FALSE]", rStrs(cols))
+	} else {
+		cols = df.ColNames()
+	}
+	if len(cols) < 2 {
+		return fmt.Errorf("pairs requires at least 2 columns, got %d", len(cols))
+	}
+	if err := checkNumeric(df, cols...); err != nil {
+		return err
+	}
+
+	var panels string
+	var cfg GraphCfg
+	if opts.Cor {
+		panels += panelCorStr
+		cfg = cfg.WithRaw("upper.panel", "panel.cor")
+	}
+	if opts.Hist {
+		panels += panelHistStr
+		cfg = cfg.WithRaw("diag.panel", "panel.hist")
+	}
+	cfg = cfg.update(opts.Cfg)
+
+	if err := rc.SendDF(df, "go.df"); err != nil {
+		return err
+	}
+	if panels == "" {
+		return rc.Rf("pairs(%s%s)", sel, cfg.params())
+	}
+	return rc.Rf("local({%s\n\tpairs(%s%s)\n})", panels, sel, cfg.params())
+}
@@ -0,0 +1,65 @@
+package rutil
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/uluyol/rgo/dataframe"
+	"github.com/uluyol/rgo/rgotest"
+)
+
+func TestPairs(t *testing.T) {
+	df := dataframe.New("a", "b", "c")
+	df.AppendURow(1.0, 2.0, 3.0)
+	df.AppendURow(2.0, 4.0, 1.0)
+
+	f := rgotest.NewFake()
+	f.Allow(".*")
+	Pairs(f, df)
+	Pairs(f, df, "a", "c")
+	if err := f.Error(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{`pairs(go.df)`, `pairs(go.df[, c("a", "c"), drop=FALSE])`}
+	if !reflect.DeepEqual(f.Cmds, want) {
+		t.Errorf("expected commands %q, got %q", want, f.Cmds)
+	}
+	if sent, _ := f.Sent("go.df"); sent != df {
+		t.Errorf("expected data frame to be sent as go.df")
+	}
+
+	f = rgotest.NewFake()
+	f.Allow(".*")
+	err := PairsWith(f, df, PairsOpts{Cor: true, Hist: true, Cfg: GraphCfg{}.WithMain("Data")}, "a", "b")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cmd := f.Cmds[0]
+	for _, s := range []string{"panel.cor <- function", "if (is.na(r))", "panel.hist <- function",
+		`pairs(go.df[, c("a", "b"), drop=FALSE], upper.panel=panel.cor, diag.panel=panel.hist, main="Data")`} {
+		if !strings.Contains(cmd, s) {
+			t.Errorf("expected command to contain %q, got\n%s", s, cmd)
+		}
+	}
+
+	if err := Pairs(rgotest.NewFake(), df, "a"); err == nil {
+		t.Errorf("expected error for a single column")
+	}
+	if err := Pairs(rgotest.NewFake(), df, "a", "nope"); err == nil {
+		t.Errorf("expected error for missing column")
+	}
+
+	mixed := dataframe.New("a", "name")
+	mixed.AppendURow(1.0, "x")
+	mixed.AppendURow(2, "y")
+	for _, cols := range [][]string{nil, {"a", "name"}} {
+		f := rgotest.NewFake()
+		if err := Pairs(f, mixed, cols...); err == nil {
+			t.Errorf("expected error for non-numeric column with columns %q", cols)
+		}
+		if len(f.Cmds) != 0 {
+			t.Errorf("expected no commands, got %q", f.Cmds)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package rutil
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Palette is an R function that returns a vector of n colors, e.g.
+// "heat.colors".
+type Palette string
+
+// OkabeItoColors are the colors of the Okabe-Ito palette, which can
+// be told apart by people with color vision deficiencies.
+var OkabeItoColors = []string{
+	"#000000", "#E69F00", "#56B4E9", "#009E73",
+	"#F0E442", "#0072B2", "#D55E00", "#CC79A7",
+}
+
+// OkabeIto is a colorblind-safe palette of OkabeItoColors for up to 8
+// series. Colors repeat after the first 8.
+var OkabeIto = Palette("function(n) rep_len(" + rStrs(OkabeItoColors) + ", n)")
+
+const (
+	// Grays runs from dark to light gray, for figures printed in
+	// black and white.
+	Grays   Palette = `function(n) gray.colors(n, start=0.1, end=0.8)`
+	Heat    Palette = "heat.colors"
+	Terrain Palette = "terrain.colors"
+)
+
+// The palettes below use hcl.colors, which was added in R 3.6. Older
+// versions of R interpolate between a few colors of the palette
+// instead.
+var (
+	Viridis = hclPalette(`"viridis"`, "#440154", "#3B528B", "#21908C", "#5DC863", "#FDE725")
+	Blues   = hclPalette(`"Blues", rev=TRUE`, "#F7FBFF", "#6BAED6", "#08306B")
+	// BlueRed is a diverging palette for values around 0.
+	BlueRed = hclPalette(`"Blue-Red"`, "#023FA5", "#E2E2E2", "#8E063B")
+)
+
+func hclPalette(args string, fallback ...string) Palette {
+	return Palette(fmt.Sprintf(`function(n) if (exists("hcl.colors")) hcl.colors(n, %s) else colorRampPalette(%s)(n)`,
+		args, rStrs(fallback)))
+}
+
+// colors returns an R expression for n colors from p.
+func (p Palette) colors(n int) string { return fmt.Sprintf("(%s)(%d)", p, n) }
+
+// Colors returns n colors from p, e.g. for use as the "col" argument.
+func (p Palette) Colors(n int) Raw { return Raw(p.colors(n)) }
+
+// Alpha returns p with the opacity of its colors set to alpha, from
+// 0 (transparent) to 1 (opaque).
+func (p Palette) Alpha(alpha float64) Palette {
+	return Palette(fmt.Sprintf("function(n) adjustcolor((%s)(n), alpha.f=%s)", p, rNum(alpha)))
+}
+
+// Alpha returns color with its opacity set to alpha, from 0
+// (transparent) to 1 (opaque).
+func Alpha(color string, alpha float64) Raw {
+	return Raw(fmt.Sprintf("adjustcolor(%s, alpha.f=%s)", strconv.Quote(color), rNum(alpha)))
+}
+
+// WithPalette colors n series using p.
+func (g GraphCfg) WithPalette(p Palette, n int) GraphCfg { return g.With("col", p.Colors(n)) }
@@ -0,0 +1,21 @@
+package rutil
+
+import "testing"
+
+func TestPalette(t *testing.T) {
+	cfg := GraphCfg{}.WithPalette(Grays.Alpha(0.5), 3)
+	want := `(function(n) adjustcolor((function(n) gray.colors(n, start=0.1, end=0.8))(n), alpha.f=0.5))(3)`
+	if got, _ := cfg.get("col"); got != want {
+		t.Errorf("expected col=%s, got %s", want, got)
+	}
+	if got := Alpha("red", 0.25); got != `adjustcolor("red", alpha.f=0.25)` {
+		t.Errorf("unexpected color %s", got)
+	}
+}
+
+func TestOkabeIto(t *testing.T) {
+	want := `function(n) rep_len(c("#000000", "#E69F00", "#56B4E9", "#009E73", "#F0E442", "#0072B2", "#D55E00", "#CC79A7"), n)`
+	if string(OkabeIto) != want {
+		t.Errorf("expected %s, got %s", want, OkabeIto)
+	}
+}
@@ -0,0 +1,115 @@
+package rutil
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/uluyol/rgo"
+)
+
+// Report draws figures on consecutive pages of a PDF document.
+// Like rgo.Conn, a Report stops at the first error and returns it
+// from all later calls.
+type Report struct {
+	rc    *rgo.Conn
+	dev   *rgo.Device
+	pages int
+	err   error
+}
+
+// reportHookStr installs hooks that record when a new plot is
+// started. Base graphics run the plot.new hook, except for persp(),
+// which has its own, and grid graphics such as lattice run the
+// grid.newpage hook.
+const reportHookStr = `..rgo.report.new <- FALSE
+..rgo.report.hook <- function() ..rgo.report.new <<- TRUE
+for (h in c("plot.new", "persp", "grid.newpage")) setHook(h, ..rgo.report.hook)`
+
+// reportUnhookStr removes the hooks installed by reportHookStr.
+const reportUnhookStr = `for (h in c("plot.new", "persp", "grid.newpage")) {
+	setHook(h, Filter(function(f) !identical(f, ..rgo.report.hook), getHook(h)), "replace")
+}`
+
+// NewReport opens a PDF device described by spec for a new Report.
+func NewReport(rc *rgo.Conn, spec rgo.PDF) (*Report, error) {
+	dev, err := rc.Device(spec)
+	if err != nil {
+		return nil, err
+	}
+	if err := rc.R(reportHookStr); err != nil {
+		dev.Close()
+		return nil, err
+	}
+	return &Report{rc: rc, dev: dev}, nil
+}
+
+// Page adds a page to the report. draw must start a new page, which
+// plotting functions such as Plot, Panels and XYPlot do; if it does
+// not, Page returns an error.
+//
+// title, if not empty, is drawn in the top outer margin of the page,
+// which Page reserves by setting par(oma=c(0, 0, 2, 0)) while draw
+// runs. Functions that set the outer margins themselves, such as
+// Panels, must leave room for it, e.g. using Layout.OuterMargin. The
+// title is drawn with base graphics, so it cannot be added to pages
+// drawn only with grid graphics such as lattice. Only the caption on
+// the page is drawn; the PDF has no bookmarks.
+func (r *Report) Page(title string, draw func() error) error {
+	if r.err != nil {
+		return r.err
+	}
+	if r.dev == nil {
+		return fmt.Errorf("report already closed")
+	}
+	if r.err = r.dev.Activate(); r.err != nil {
+		return r.err
+	}
+	oma := "c(0, 0, 0, 0)"
+	if title != "" {
+		oma = "c(0, 0, 2, 0)"
+	}
+	if r.err = r.rc.Rf("..rgo.report.new <- FALSE\n..rgo.report.par <- par(oma=%s)", oma); r.err != nil {
+		return r.err
+	}
+	if r.err = draw(); r.err != nil {
+		return r.err
+	}
+	var started []bool
+	if r.err = r.rc.Get(&started, "..rgo.report.new"); r.err != nil {
+		return r.err
+	}
+	if len(started) != 1 || !started[0] {
+		r.err = fmt.Errorf("page %d: draw did not start a new page", r.pages+1)
+		return r.err
+	}
+	if title != "" {
+		r.err = r.rc.Rf("mtext(%s, side=3, outer=TRUE, line=0.5, font=2, cex=1.2)", strconv.Quote(title))
+		if r.err != nil {
+			return r.err
+		}
+	}
+	if r.err = r.rc.R("par(..rgo.report.par)"); r.err != nil {
+		return r.err
+	}
+	r.pages++
+	return nil
+}
+
+// Pages returns the number of pages added to the report.
+func (r *Report) Pages() int { return r.pages }
+
+// Close closes the PDF device and returns the document.
+func (r *Report) Close() ([]byte, error) {
+	if r.dev == nil {
+		return nil, fmt.Errorf("report already closed")
+	}
+	b, err := r.dev.Close()
+	r.dev = nil
+	if uerr := r.rc.R(reportUnhookStr); err == nil {
+		err = uerr
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return b, err
+}
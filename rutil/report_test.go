@@ -0,0 +1,70 @@
+package rutil
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/uluyol/rgo"
+	"github.com/uluyol/rgo/rgotest"
+)
+
+func TestReport(t *testing.T) {
+	c := rgotest.NewConn(t)
+	defer c.Close()
+
+	r, err := NewReport(c, rgo.PDF{Width: 5, Height: 4})
+	if err != nil {
+		t.Fatalf("failed to create report: %v", err)
+	}
+	for _, title := range []string{"First", ""} {
+		err := r.Page(title, func() error {
+			return PlotX(c, []float64{1, 2, 3}, GraphCfg{})
+		})
+		if err != nil {
+			t.Fatalf("failed to add page: %v", err)
+		}
+	}
+	if r.Pages() != 2 {
+		t.Errorf("expected 2 pages, got %d", r.Pages())
+	}
+	err = r.Page("Persp", func() error {
+		z, _ := NewMatrix([][]float64{{1, 2}, {3, 4}})
+		return Persp(c, Surface{X: []float64{0, 1}, Y: []float64{0, 1}, Z: z}, PerspOpts{})
+	})
+	if err != nil {
+		t.Fatalf("failed to add persp page: %v", err)
+	}
+	b, err := r.Close()
+	if err != nil {
+		t.Fatalf("failed to close report: %v", err)
+	}
+	if !bytes.HasPrefix(b, []byte("%PDF")) {
+		t.Errorf("expected a PDF document")
+	}
+	if _, err := r.Close(); err == nil {
+		t.Errorf("expected error closing report twice")
+	}
+	var hooks []int
+	if err := c.Get(&hooks, "length(getHook(\"plot.new\"))"); err != nil || len(hooks) != 1 || hooks[0] != 0 {
+		t.Errorf("expected hooks to be removed, got %v (%v)", hooks, err)
+	}
+}
+
+func TestReportNoPage(t *testing.T) {
+	c := rgotest.NewConn(t)
+	defer c.Close()
+
+	r, err := NewReport(c, rgo.PDF{Width: 5, Height: 4})
+	if err != nil {
+		t.Fatalf("failed to create report: %v", err)
+	}
+	if err := r.Page("Empty", func() error { return nil }); err == nil {
+		t.Errorf("expected error for a page that was not started")
+	}
+	if r.Pages() != 0 {
+		t.Errorf("expected no pages, got %d", r.Pages())
+	}
+	if _, err := r.Close(); err == nil {
+		t.Errorf("expected error closing report")
+	}
+}
@@ -0,0 +1,154 @@
+package rutil
+
+import (
+	"fmt"
+
+	"github.com/uluyol/rgo"
+)
+
+// Surface is a function of two variables sampled on a grid. Z has a
+// row for each value in X and a column for each value in Y, so the
+// value at (X[i], Y[j]) is Z.At(i, j). X and Y must be increasing and
+// have at least 2 values each.
+type Surface struct {
+	X, Y []float64
+	Z    *Matrix
+}
+
+func increasing(v []float64) bool {
+	for i := 1; i < len(v); i++ {
+		if v[i] <= v[i-1] {
+			return false
+		}
+	}
+	return true
+}
+
+// send sends s to R as go.sx, go.sy and go.sz.
+func (s *Surface) send(rc rgo.Executor) error {
+	if s.Z == nil {
+		return fmt.Errorf("surface has no values")
+	}
+	if s.Z.Rows < 2 || s.Z.Cols < 2 {
+		return fmt.Errorf("surface requires at least a 2 by 2 grid, got %d by %d", s.Z.Rows, s.Z.Cols)
+	}
+	if len(s.X) != s.Z.Rows || len(s.Y) != s.Z.Cols {
+		return fmt.Errorf("got %d x and %d y values for a %d by %d grid", len(s.X), len(s.Y), s.Z.Rows, s.Z.Cols)
+	}
+	if !increasing(s.X) || !increasing(s.Y) {
+		return fmt.Errorf("grid coordinates must be increasing")
+	}
+	if err := s.Z.validate(); err != nil {
+		return err
+	}
+	if err := sendFloats(rc, s.X, "go.sx"); err != nil {
+		return err
+	}
+	if err := sendFloats(rc, s.Y, "go.sy"); err != nil {
+		return err
+	}
+	return s.Z.send(rc, "go.sz")
+}
+
+// ContourOpts configures Contour and FilledContour.
+type ContourOpts struct {
+	// Levels are the values at which to draw contours. If not set,
+	// R picks about NLevels levels (by default 10).
+	Levels  []float64
+	NLevels int
+	// Palette colors the levels of FilledContour. It is Viridis if
+	// not set.
+	Palette Palette
+	// Cfg holds additional arguments for contour() or
+	// filled.contour().
+	Cfg GraphCfg
+}
+
+func (o *ContourOpts) levels() GraphCfg {
+	var cfg GraphCfg
+	if o.Levels != nil {
+		cfg = cfg.With("levels", o.Levels)
+	}
+	if o.NLevels > 0 {
+		cfg = cfg.With("nlevels", o.NLevels)
+	}
+	return cfg
+}
+
+// Contour draws contour lines of s.
+func Contour(rc rgo.Executor, s Surface, opts ContourOpts) error {
+	if err := opts.Cfg.Err(); err != nil {
+		return err
+	}
+	if err := s.send(rc); err != nil {
+		return err
+	}
+	return rc.Rf("contour(go.sx, go.sy, go.sz%s)", opts.levels().update(opts.Cfg).params())
+}
+
+// FilledContour draws s with the areas between contour levels filled
+// and a color scale. It uses the whole device, so it cannot be used
+// within Panels.
+func FilledContour(rc rgo.Executor, s Surface, opts ContourOpts) error {
+	if err := opts.Cfg.Err(); err != nil {
+		return err
+	}
+	if err := s.send(rc); err != nil {
+		return err
+	}
+	p := opts.Palette
+	if p == "" {
+		p = Viridis
+	}
+	cfg := opts.levels().WithRaw("color.palette", string(p)).update(opts.Cfg)
+	return rc.Rf("filled.contour(go.sx, go.sy, go.sz%s)", cfg.params())
+}
+
+// PerspOpts configures Persp.
+type PerspOpts struct {
+	// Theta and Phi give the direction the surface is viewed from:
+	// Theta is the azimuth and Phi the colatitude in degrees. Zero
+	// values use R's defaults of 0 and 15. Use Cfg to set phi=0.
+	Theta, Phi float64
+	// Shade, if positive, shades the surface as if lit from the
+	// viewing direction. Values around 0.5 work well.
+	Shade float64
+	// Palette, if set, colors each facet by its height.
+	Palette Palette
+	// Cfg holds additional arguments for persp().
+	Cfg GraphCfg
+}
+
+const perspStr = `local({
+	z <- go.sz
+	nr <- nrow(z)
+	nc <- ncol(z)
+	zf <- (z[-1, -1] + z[-1, -nc] + z[-nr, -1] + z[-nr, -nc]) / 4
+	cols <- %s
+	persp(go.sx, go.sy, z, col=cols[cut(zf, length(cols))]%s)
+})`
+
+// Persp draws s as a 3D surface.
+func Persp(rc rgo.Executor, s Surface, opts PerspOpts) error {
+	if err := opts.Cfg.Err(); err != nil {
+		return err
+	}
+	if err := s.send(rc); err != nil {
+		return err
+	}
+	var cfg GraphCfg
+	if opts.Theta != 0 {
+		cfg = cfg.With("theta", opts.Theta)
+	}
+	if opts.Phi != 0 {
+		cfg = cfg.With("phi", opts.Phi)
+	}
+	if opts.Shade > 0 {
+		cfg = cfg.With("shade", opts.Shade)
+	}
+	cfg = cfg.update(opts.Cfg)
+	if opts.Palette == "" {
+		return rc.Rf("persp(go.sx, go.sy, go.sz%s)", cfg.params())
+	}
+	return rc.Rf(perspStr, opts.Palette.colors(64), cfg.params())
+}
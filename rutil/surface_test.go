@@ -0,0 +1,71 @@
+package rutil
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/uluyol/rgo/rgotest"
+)
+
+func testSurface() Surface {
+	z, _ := NewMatrix([][]float64{{1, 2, 3}, {4, 5, 6}})
+	return Surface{X: []float64{0, 1}, Y: []float64{0, 1, 2}, Z: z}
+}
+
+func TestSurfacePlots(t *testing.T) {
+	f := rgotest.NewFake()
+	f.Allow(".*")
+	s := testSurface()
+	Contour(f, s, ContourOpts{Levels: []float64{2, 4}, Cfg: GraphCfg{}.WithCol("gray")})
+	FilledContour(f, s, ContourOpts{NLevels: 5, Palette: Heat})
+	Persp(f, s, PerspOpts{Theta: 30, Phi: 20, Shade: 0.5})
+	if err := f.Error(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	zcmd := `go.sz <- matrix(as.double(go.sz), nrow=2, byrow=TRUE)`
+	want := []string{
+		zcmd,
+		`contour(go.sx, go.sy, go.sz, levels=c(2, 4), col="gray")`,
+		zcmd,
+		`filled.contour(go.sx, go.sy, go.sz, nlevels=5, color.palette=heat.colors)`,
+		zcmd,
+		`persp(go.sx, go.sy, go.sz, theta=30, phi=20, shade=0.5)`,
+	}
+	if !reflect.DeepEqual(f.Cmds, want) {
+		t.Errorf("expected commands\n%q\ngot\n%q", want, f.Cmds)
+	}
+
+	f = rgotest.NewFake()
+	f.Allow(".*")
+	if err := Persp(f, s, PerspOpts{Palette: Viridis}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if last := f.Cmds[len(f.Cmds)-1]; !strings.Contains(last, "col=cols[cut(zf, length(cols))])") {
+		t.Errorf("expected facets to be colored, got\n%s", last)
+	}
+}
+
+func TestSurfaceInvalid(t *testing.T) {
+	s := testSurface()
+	s.X = []float64{1, 0}
+	row, _ := NewMatrix([][]float64{{1, 2, 3}})
+	empty, _ := NewMatrix(nil)
+	bad := []Surface{
+		{},
+		{X: []float64{0}, Y: testSurface().Y, Z: testSurface().Z},
+		s,
+		{X: []float64{0}, Y: testSurface().Y, Z: row},
+		{Z: empty},
+		{X: []float64{0, 1}, Y: []float64{0, 1}, Z: &Matrix{Rows: 2, Cols: 2}},
+	}
+	for i, s := range bad {
+		f := rgotest.NewFake()
+		if err := Contour(f, s, ContourOpts{}); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+		if len(f.Cmds) != 0 {
+			t.Errorf("case %d: expected no commands, got %q", i, f.Cmds)
+		}
+	}
+}
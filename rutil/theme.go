@@ -0,0 +1,98 @@
+package rutil
+
+import (
+	"strings"
+
+	"github.com/uluyol/rgo"
+)
+
+// Theme sets the sizes and colors of figures so that they look
+// consistent. Text size is set by the device, so use the device specs
+// returned by a Theme when rendering.
+type Theme struct {
+	// Width and Height are the size of the figure in inches.
+	Width, Height float64
+	// PointSize is the size of text.
+	PointSize float64
+	// Lwd is the default line width.
+	Lwd float64
+	// Mar and Mgp are the margins and the position of axis titles,
+	// labels and lines, in lines of text. See par().
+	Mar []float64
+	Mgp []float64
+	// Palette sets the colors used for col=1, col=2 and so on.
+	Palette Palette
+}
+
+var (
+	// PaperSingleColumn fits a single column of a two-column paper.
+	PaperSingleColumn = Theme{
+		Width: 3.3, Height: 2.2, PointSize: 8, Lwd: 1,
+		Mar: []float64{3, 3, 1, 1}, Mgp: []float64{1.8, 0.6, 0}, Palette: OkabeIto,
+	}
+	// PaperTwoColumn spans both columns of a two-column paper.
+	PaperTwoColumn = Theme{
+		Width: 7, Height: 2.5, PointSize: 9, Lwd: 1,
+		Mar: []float64{3, 3, 1, 1}, Mgp: []float64{1.8, 0.6, 0}, Palette: OkabeIto,
+	}
+	// Slide fills a 16:9 presentation slide.
+	Slide = Theme{
+		Width: 10, Height: 5.6, PointSize: 18, Lwd: 2.5,
+		Mar: []float64{4, 4.5, 1.5, 1}, Mgp: []float64{2.8, 0.9, 0}, Palette: OkabeIto,
+	}
+)
+
+// Apply sets the graphical parameters of the current device to t.
+func (t Theme) Apply(rc rgo.Executor) error {
+	var par GraphCfg
+	if t.Mar != nil {
+		par = par.With("mar", t.Mar)
+	}
+	if t.Mgp != nil {
+		par = par.With("mgp", t.Mgp)
+	}
+	if t.Lwd > 0 {
+		par = par.WithLwd(t.Lwd)
+	}
+	if len(par.args) > 0 {
+		if err := rc.Rf("par(%s)", strings.TrimPrefix(par.params(), ", ")); err != nil {
+			return err
+		}
+	}
+	if t.Palette != "" {
+		return rc.Rf("palette(%s)", t.Palette.colors(8))
+	}
+	return nil
+}
+
+// PDF returns a PDF device spec with the size of t.
+func (t Theme) PDF() rgo.PDF {
+	return rgo.PDF{Width: t.Width, Height: t.Height, PointSize: t.PointSize}
+}
+
+// SVG returns an SVG device spec with the size of t.
+func (t Theme) SVG() rgo.SVG {
+	return rgo.SVG{Width: t.Width, Height: t.Height, PointSize: t.PointSize}
+}
+
+// PNG returns a PNG device spec with the size of t at dpi pixels per
+// inch.
+func (t Theme) PNG(dpi int) rgo.PNG {
+	return rgo.PNG{
+		Width:     int(t.Width*float64(dpi) + 0.5),
+		Height:    int(t.Height*float64(dpi) + 0.5),
+		DPI:       dpi,
+		PointSize: t.PointSize,
+	}
+}
+
+// Draw is like DrawTo but applies t to the device before calling
+// draw.
+func (t Theme) Draw(rc *rgo.Conn, spec rgo.DeviceSpec, draw func() error) ([]byte, error) {
+	return DrawTo(rc, spec, func() error {
+		if err := t.Apply(rc); err != nil {
+			return err
+		}
+		return draw()
+	})
+}
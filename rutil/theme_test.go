@@ -0,0 +1,36 @@
+package rutil
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/uluyol/rgo"
+	"github.com/uluyol/rgo/rgotest"
+)
+
+func TestTheme(t *testing.T) {
+	f := rgotest.NewFake()
+	f.Allow(".*")
+	if err := PaperSingleColumn.Apply(f); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{
+		`par(mar=c(3, 3, 1, 1), mgp=c(1.8, 0.6, 0), lwd=1)`,
+		"palette(" + OkabeIto.colors(8) + ")",
+	}
+	if !reflect.DeepEqual(f.Cmds, want) {
+		t.Errorf("expected commands\n%q\ngot\n%q", want, f.Cmds)
+	}
+
+	f = rgotest.NewFake()
+	if err := (Theme{}).Apply(f); err != nil || len(f.Cmds) != 0 {
+		t.Errorf("expected empty theme to do nothing, got %v, %q", err, f.Cmds)
+	}
+
+	if got, want := Slide.PNG(100), (rgo.PNG{Width: 1000, Height: 560, DPI: 100, PointSize: 18}); got != want {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+	if got, want := PaperTwoColumn.PDF(), (rgo.PDF{Width: 7, Height: 2.5, PointSize: 9}); got != want {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
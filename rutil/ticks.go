@@ -0,0 +1,113 @@
+package rutil
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/uluyol/rgo"
+)
+
+// TickFormatter formats the value of a tick as its label.
+type TickFormatter func(v float64) string
+
+// FormatTicks returns the label of each tick in at.
+func FormatTicks(at []float64, f TickFormatter) []string {
+	labels := make([]string, len(at))
+	for i, v := range at {
+		labels[i] = f(v)
+	}
+	return labels
+}
+
+// round3 rounds v to 3 significant digits.
+func round3(v float64) float64 {
+	r, _ := strconv.ParseFloat(strconv.FormatFloat(v, 'g', 3, 64), 64)
+	return r
+}
+
+func fmtScaled(v float64) string {
+	return strconv.FormatFloat(round3(v), 'f', -1, 64)
+}
+
+// scale formats v using the largest unit that is at most |v|.
+// units[i] is worth base^(i+off).
+func scale(v, base float64, off int, units []string) string {
+	if v == 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+		return fmtScaled(v) + units[-off]
+	}
+	i := int(math.Floor(math.Log(math.Abs(v))/math.Log(base))) - off
+	if i < 0 {
+		i = 0
+	} else if i >= len(units) {
+		i = len(units) - 1
+	}
+	// Rounding may carry over into the next unit, e.g. 999.9 -> 1K.
+	if math.Abs(round3(v/math.Pow(base, float64(i+off)))) >= base && i+1 < len(units) {
+		i++
+	}
+	return fmtScaled(v/math.Pow(base, float64(i+off))) + units[i]
+}
+
+var siUnits = []string{"p", "n", "u", "m", "", "K", "M", "G", "T", "P"}
+
+// SI formats v using SI prefixes, e.g. 1500 as "1.5K" and 0.01 as
+// "10m".
+func SI(v float64) string { return scale(v, 1000, -4, siUnits) }
+
+var byteUnits = []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB"}
+
+// Bytes formats a number of bytes using binary prefixes, e.g. 1024 as
+// "1KiB".
+func Bytes(v float64) string { return scale(v, 1024, 0, byteUnits) }
+
+// Duration returns a TickFormatter for durations measured in unit,
+// e.g. Duration(time.Millisecond) formats 10 as "10ms".
+func Duration(unit time.Duration) TickFormatter {
+	return func(v float64) string {
+		return time.Duration(v * float64(unit)).String()
+	}
+}
+
+// LogTicks returns the powers of 10 from below lo to above hi, for
+// ticks on a log axis. It returns nil unless 0 < lo <= hi.
+func LogTicks(lo, hi float64) []float64 {
+	if lo <= 0 || hi < lo {
+		return nil
+	}
+	var ticks []float64
+	for e := math.Floor(math.Log10(lo)); e <= math.Ceil(math.Log10(hi)); e++ {
+		ticks = append(ticks, math.Pow(10, e))
+	}
+	return ticks
+}
+
+const rotatedAxisStr = `local({
+	axis(%[1]d, at=%[2]s, labels=FALSE%[3]s)
+	text(%[4]s, labels=%[5]s, srt=%[6]s, adj=%[7]s, xpd=TRUE)
+})`
+
+// RotatedAxis is like Axis but rotates the labels by angle degrees,
+// which is useful for long labels that overlap. Only the bottom and
+// left axes are supported.
+func RotatedAxis(rc rgo.Executor, side Side, at []float64, labels []string, angle float64, cfg GraphCfg) error {
+	if err := cfg.Err(); err != nil {
+		return err
+	}
+	if len(labels) != len(at) {
+		return fmt.Errorf("got %d labels for %d ticks", len(labels), len(at))
+	}
+	var pos, adj string
+	switch side {
+	case Bottom:
+		pos = fmt.Sprintf(`%s, grconvertY(-0.03, "npc", "user")`, rNums(at))
+		adj = "c(1, 1)"
+	case Left:
+		pos = fmt.Sprintf(`grconvertX(-0.03, "npc", "user"), %s`, rNums(at))
+		adj = "c(1, 0.5)"
+	default:
+		return fmt.Errorf("rotated labels are not supported on side %d", side)
+	}
+	return rc.Rf(rotatedAxisStr, side, rNums(at), cfg.params(), pos, rStrs(labels), rNum(angle), adj)
+}
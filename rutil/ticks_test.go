@@ -0,0 +1,86 @@
+package rutil
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/uluyol/rgo/rgotest"
+)
+
+func TestTickFormatters(t *testing.T) {
+	tests := []struct {
+		f    TickFormatter
+		v    float64
+		want string
+	}{
+		{SI, 0, "0"},
+		{SI, 1, "1"},
+		{SI, 1500, "1.5K"},
+		{SI, 999.9, "1K"},
+		{SI, 2e6, "2M"},
+		{SI, 0.01, "10m"},
+		{SI, -3e9, "-3G"},
+		{SI, 1e20, "100000P"},
+		{Bytes, 512, "512B"},
+		{Bytes, 1024, "1KiB"},
+		{Bytes, 1.5 * (1 << 30), "1.5GiB"},
+		{Duration(time.Millisecond), 10, "10ms"},
+		{Duration(time.Second), 0.5, "500ms"},
+		{Duration(time.Microsecond), 1500, "1.5ms"},
+	}
+	for _, test := range tests {
+		if got := test.f(test.v); got != test.want {
+			t.Errorf("formatting %v: got %q, want %q", test.v, got, test.want)
+		}
+	}
+}
+
+func TestLogTicks(t *testing.T) {
+	want := []float64{0.1, 1, 10, 100}
+	if got := LogTicks(0.5, 99); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := LogTicks(0, 10); got != nil {
+		t.Errorf("expected no ticks for non-positive range, got %v", got)
+	}
+	if got := FormatTicks(LogTicks(1000, 1e6), SI); !reflect.DeepEqual(got, []string{"1K", "10K", "100K", "1M"}) {
+		t.Errorf("unexpected labels %q", got)
+	}
+}
+
+func TestLogAxes(t *testing.T) {
+	tests := []struct {
+		cfg  GraphCfg
+		want string
+	}{
+		{GraphCfg{}.WithLogX(), `"x"`},
+		{GraphCfg{}.WithLogX().WithLogY(), `"xy"`},
+		{GraphCfg{}.WithLog("y").WithLogX(), `"xy"`},
+		{GraphCfg{}.WithLogY().WithLogY(), `"y"`},
+	}
+	for i, test := range tests {
+		if got, _ := test.cfg.get("log"); got != test.want {
+			t.Errorf("case %d: got log=%s, want %s", i, got, test.want)
+		}
+	}
+}
+
+func TestRotatedAxis(t *testing.T) {
+	f := rgotest.NewFake()
+	f.Allow(".*")
+	err := RotatedAxis(f, Bottom, []float64{1, 2}, []string{"a", "b"}, 45, GraphCfg{}.WithLwd(2))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `local({
+	axis(1, at=c(1, 2), labels=FALSE, lwd=2)
+	text(c(1, 2), grconvertY(-0.03, "npc", "user"), labels=c("a", "b"), srt=45, adj=c(1, 1), xpd=TRUE)
+})`
+	if len(f.Cmds) != 1 || f.Cmds[0] != want {
+		t.Errorf("expected command\n%s\ngot\n%q", want, f.Cmds)
+	}
+	if err := RotatedAxis(f, Top, nil, nil, 45, GraphCfg{}); err == nil {
+		t.Errorf("expected error for top axis")
+	}
+}
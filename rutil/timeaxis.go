@@ -0,0 +1,211 @@
+package rutil
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/uluyol/rgo"
+)
+
+// TimeAxis configures the time axis drawn by PlotTime.
+type TimeAxis struct {
+	// Layout is a Go time layout, e.g. "15:04" or "Jan 2", used for
+	// the labels. If empty, a format is chosen based on the range
+	// of the times.
+	Layout string
+	// At, if set, are the positions of the ticks. Otherwise R
+	// picks them.
+	At []time.Time
+}
+
+// rTZ returns the R time zone for loc. Times should use locations
+// loaded from the time zone database, or UTC or Local.
+func rTZ(loc *time.Location) string {
+	if loc == time.Local {
+		return ""
+	}
+	return loc.String()
+}
+
+func unixSecs(ts []time.Time) []float64 {
+	secs := make([]float64, len(ts))
+	for i, t := range ts {
+		secs[i] = float64(t.Unix()) + float64(t.Nanosecond())/1e9
+	}
+	return secs
+}
+
+// sendTimes sends ts to R as a POSIXct vector in the time zone of
+// the first time.
+func sendTimes(rc rgo.Executor, ts []time.Time, name string) error {
+	if err := rc.Send(unixSecs(ts), name); err != nil {
+		return err
+	}
+	tz := ""
+	if len(ts) > 0 {
+		tz = rTZ(ts[0].Location())
+	}
+	return rc.Rf("%s <- as.POSIXct(as.double(%s), origin=\"1970-01-01\", tz=%s)", name, name, strconv.Quote(tz))
+}
+
+// layoutTokens maps the elements of Go time layouts to strftime
+// conversions. Longer elements come first so that they are matched
+// before their prefixes.
+var layoutTokens = []struct{ goFmt, rFmt string }{
+	{"January", "%B"},
+	{"Monday", "%A"},
+	{"2006", "%Y"},
+	{"-07:00", "%z"},
+	{"-0700", "%z"},
+	{"Jan", "%b"},
+	{"Mon", "%a"},
+	{"MST", "%Z"},
+	{"01", "%m"},
+	{"02", "%d"},
+	{"_2", "%e"},
+	{"15", "%H"},
+	{"03", "%I"},
+	{"04", "%M"},
+	{"05", "%S"},
+	{"06", "%y"},
+	{"PM", "%p"},
+	{"pm", "%p"},
+	{"1", "%m"},
+	{"2", "%d"},
+	{"3", "%I"},
+	{"4", "%M"},
+	{"5", "%S"},
+}
+
+// strftimeLayout converts a Go time layout into a format for R's
+// strftime. Elements without an unpadded equivalent are padded.
+func strftimeLayout(layout string) string {
+	var b bytes.Buffer
+	for len(layout) > 0 {
+		matched := false
+		for _, t := range layoutTokens {
+			if !strings.HasPrefix(layout, t.goFmt) {
+				continue
+			}
+			layout = layout[len(t.goFmt):]
+			if t.rFmt == "%S" {
+				// Fractional seconds, e.g. "05.000".
+				n := 0
+				if len(layout) > 1 && layout[0] == '.' && (layout[1] == '0' || layout[1] == '9') {
+					for n+1 < len(layout) && layout[n+1] == layout[1] {
+						n++
+					}
+				}
+				if n > 0 {
+					b.WriteString("%OS" + strconv.Itoa(n))
+					layout = layout[n+1:]
+					matched = true
+					break
+				}
+			}
+			b.WriteString(t.rFmt)
+			matched = true
+			break
+		}
+		if !matched {
+			if layout[0] == '%' {
+				b.WriteByte('%')
+			}
+			b.WriteByte(layout[0])
+			layout = layout[1:]
+		}
+	}
+	return b.String()
+}
+
+// autoFormat picks a strftime format to label times spanning span.
+func autoFormat(span time.Duration) string {
+	switch {
+	case span < time.Minute:
+		return "%H:%M:%S"
+	case span < 24*time.Hour:
+		return "%H:%M"
+	case span < 7*24*time.Hour:
+		return "%b %d %H:%M"
+	case span < 365*24*time.Hour:
+		return "%b %d"
+	}
+	return "%Y-%m"
+}
+
+func timeSpan(ts []time.Time) time.Duration {
+	if len(ts) == 0 {
+		return 0
+	}
+	min, max := ts[0], ts[0]
+	for _, t := range ts[1:] {
+		if t.Before(min) {
+			min = t
+		}
+		if t.After(max) {
+			max = t
+		}
+	}
+	return max.Sub(min)
+}
+
+// PlotTime plots y against the times x. The x axis is labeled using
+// ax. Times are shown in the time zone of x[0].
+func PlotTime(rc rgo.Executor, x []time.Time, y []float64, ax TimeAxis, cfg GraphCfg) error {
+	if err := cfg.Err(); err != nil {
+		return err
+	}
+	if len(x) != len(y) {
+		return fmt.Errorf("got %d times for %d values", len(x), len(y))
+	}
+	if len(x) == 0 {
+		return fmt.Errorf("no values to plot")
+	}
+	format := autoFormat(timeSpan(x))
+	if ax.Layout != "" {
+		format = strftimeLayout(ax.Layout)
+	}
+	if err := sendTimes(rc, x, "go.t"); err != nil {
+		return err
+	}
+	if err := sendFloats(rc, y, "go.y"); err != nil {
+		return err
+	}
+	if err := rc.Rf("plot(go.t, go.y, xaxt=\"n\"%s)", cfg.params()); err != nil {
+		return err
+	}
+	axisCfg := GraphCfg{}.With("format", format)
+	if ax.At != nil {
+		// Labels are formatted in the time zone of the plotted times.
+		at := make([]time.Time, len(ax.At))
+		for i, t := range ax.At {
+			at[i] = t.In(x[0].Location())
+		}
+		if err := sendTimes(rc, at, "go.at"); err != nil {
+			return err
+		}
+		axisCfg = axisCfg.WithRaw("at", "go.at")
+	}
+	return rc.Rf("axis.POSIXct(1, go.t%s)", axisCfg.params())
+}
+
+// LinesTime adds a line through y against the times x to a plot
+// created by PlotTime.
+func LinesTime(rc rgo.Executor, x []time.Time, y []float64, cfg GraphCfg) error {
+	if err := cfg.Err(); err != nil {
+		return err
+	}
+	if len(x) != len(y) {
+		return fmt.Errorf("got %d times for %d values", len(x), len(y))
+	}
+	if err := sendTimes(rc, x, "go.t"); err != nil {
+		return err
+	}
+	if err := sendFloats(rc, y, "go.y"); err != nil {
+		return err
+	}
+	return rc.Rf("lines(go.t, go.y%s)", cfg.params())
+}
@@ -0,0 +1,74 @@
+package rutil
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/uluyol/rgo/rgotest"
+)
+
+func TestStrftimeLayout(t *testing.T) {
+	tests := []struct{ layout, want string }{
+		{"15:04", "%H:%M"},
+		{"Jan 2", "%b %d"},
+		{"2006-01-02 15:04:05", "%Y-%m-%d %H:%M:%S"},
+		{"Monday, January _2 3:04PM MST", "%A, %B %e %I:%M%p %Z"},
+		{"04:05.000", "%M:%OS3"},
+		{"15h (%)", "%Hh (%%)"},
+	}
+	for _, test := range tests {
+		if got := strftimeLayout(test.layout); got != test.want {
+			t.Errorf("converting %q: got %q, want %q", test.layout, got, test.want)
+		}
+	}
+}
+
+func TestPlotTime(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone database not available: %v", err)
+	}
+	start := time.Date(2020, 1, 1, 12, 0, 0, 500000000, loc)
+	x := []time.Time{start, start.Add(time.Hour)}
+
+	f := rgotest.NewFake()
+	f.Allow(".*")
+	if err := PlotTime(f, x, []float64{1, 2}, TimeAxis{}, GraphCfg{}.WithType("l")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	LinesTime(f, x, []float64{2, 3}, GraphCfg{}.WithCol("red"))
+	want := []string{
+		`go.t <- as.POSIXct(as.double(go.t), origin="1970-01-01", tz="America/New_York")`,
+		`plot(go.t, go.y, xaxt="n", type="l")`,
+		`axis.POSIXct(1, go.t, format="%H:%M")`,
+		`go.t <- as.POSIXct(as.double(go.t), origin="1970-01-01", tz="America/New_York")`,
+		`lines(go.t, go.y, col="red")`,
+	}
+	if !reflect.DeepEqual(f.Cmds, want) {
+		t.Errorf("expected commands\n%q\ngot\n%q", want, f.Cmds)
+	}
+	if sent, _ := f.Sent("go.t"); !reflect.DeepEqual(sent, []float64{1577898000.5, 1577901600.5}) {
+		t.Errorf("unexpected times sent: %v", sent)
+	}
+
+	f = rgotest.NewFake()
+	f.Allow(".*")
+	ax := TimeAxis{Layout: "Jan 2", At: []time.Time{start.In(time.Local)}}
+	if err := PlotTime(f, x, []float64{1, 2}, ax, GraphCfg{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want = []string{
+		`go.t <- as.POSIXct(as.double(go.t), origin="1970-01-01", tz="America/New_York")`,
+		`plot(go.t, go.y, xaxt="n")`,
+		`go.at <- as.POSIXct(as.double(go.at), origin="1970-01-01", tz="America/New_York")`,
+		`axis.POSIXct(1, go.t, format="%b %d", at=go.at)`,
+	}
+	if !reflect.DeepEqual(f.Cmds, want) {
+		t.Errorf("expected commands\n%q\ngot\n%q", want, f.Cmds)
+	}
+
+	if err := PlotTime(rgotest.NewFake(), x, nil, TimeAxis{}, GraphCfg{}); err == nil {
+		t.Errorf("expected error for mismatched lengths")
+	}
+}
@@ -0,0 +1,97 @@
+package rgo
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/uluyol/rgo/dataframe"
+)
+
+// ScriptFile is the name of the script written by a Script.
+const ScriptFile = "script.R"
+
+// ErrScriptMode is returned by Script.Get, as no R process is
+// available to retrieve data from.
+var ErrScriptMode = errors.New("unavailable in script mode")
+
+// Script is an Executor that writes R code instead of running it.
+// Commands are appended to ScriptFile and data passed to Send and
+// SendDF is written into files alongside it, along with the code to
+// read them. The resulting script can be run from its directory
+// with Rscript.
+//
+// Like Conn, Script stops at the first error and returns it from
+// all later operations. Get is the exception: it always returns
+// ErrScriptMode.
+type Script struct {
+	w   *scriptWriter
+	err error
+}
+
+// NewScript creates a Script that writes into dir.
+func NewScript(dir string) (*Script, error) {
+	w, err := newScriptWriter(dir, ScriptFile)
+	if err != nil {
+		return nil, err
+	}
+	return &Script{w: w}, nil
+}
+
+// R appends cmd to the script.
+func (s *Script) R(cmd string) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.err = s.w.command(cmd, nil)
+	return s.err
+}
+
+// Rf is like R but takes a format string and arguments.
+func (s *Script) Rf(format string, args ...interface{}) error {
+	return s.R(fmt.Sprintf(format, args...))
+}
+
+// Send writes data into a file and appends the code to read it
+// into name. data must be json-serializable.
+func (s *Script) Send(data interface{}, name string) error {
+	if s.err != nil {
+		return s.err
+	}
+	b, err := json.Marshal(data)
+	if err != nil {
+		s.err = errors.Wrap(err, "unable to serialize data")
+		return s.err
+	}
+	cmd, err := s.w.data(b, name)
+	if err != nil {
+		s.err = err
+		return err
+	}
+	return s.R(cmd)
+}
+
+// SendDF is like Send but unpacks df as an R data frame.
+func (s *Script) SendDF(df dataframe.DataFrame, name string) error {
+	return sendDF(s, df, name)
+}
+
+// Get returns ErrScriptMode.
+func (s *Script) Get(data interface{}, name string) error {
+	return ErrScriptMode
+}
+
+// Error returns the first error that occured while writing the
+// script.
+func (s *Script) Error() error {
+	return s.err
+}
+
+// Close flushes and closes the script.
+func (s *Script) Close() error {
+	err := s.w.close()
+	if s.err == nil {
+		s.err = err
+	}
+	return err
+}
@@ -0,0 +1,74 @@
+package rgo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/uluyol/rgo/dataframe"
+)
+
+func TestScript(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rgo-script")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	s, err := NewScript(dir)
+	if err != nil {
+		t.Fatalf("failed to create script: %v", err)
+	}
+	if err := s.Send([]float64{1, 2}, "x"); err != nil {
+		t.Errorf("unexpected error sending data: %v", err)
+	}
+	df := dataframe.New("a", "b")
+	df.AppendURow(1, "x")
+	if err := s.SendDF(df, "df"); err != nil {
+		t.Errorf("unexpected error sending data frame: %v", err)
+	}
+	if err := s.Rf("plot(x, %s)", "x"); err != nil {
+		t.Errorf("unexpected error writing command: %v", err)
+	}
+	var x []float64
+	if err := s.Get(&x, "x"); err != ErrScriptMode {
+		t.Errorf("expected ErrScriptMode from Get, got %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("failed to close script: %v", err)
+	}
+	if s.Error() != nil {
+		t.Errorf("unexpected error: %v", s.Error())
+	}
+
+	f, err := os.Open(filepath.Join(dir, ScriptFile))
+	if err != nil {
+		t.Fatalf("failed to open script: %v", err)
+	}
+	defer f.Close()
+	cmds, err := readScript(f)
+	if err != nil {
+		t.Fatalf("failed to read script: %v", err)
+	}
+	want := []string{
+		`x = fromJSON(file.path(..rgo.dir, "data-0.json"))`,
+		`..rgo.df.cols.0 = fromJSON(file.path(..rgo.dir, "data-1.json"))`,
+		"..rgo.df.cols.0 <- as.double(..rgo.df.cols.0)",
+		`..rgo.df.cols.1 = fromJSON(file.path(..rgo.dir, "data-2.json"))`,
+		`..rgo.df.colNames = fromJSON(file.path(..rgo.dir, "data-3.json"))`,
+		`..rgo.df.rowNames = fromJSON(file.path(..rgo.dir, "data-4.json"))`,
+		"..rgo.df.result <- data.frame(..rgo.df.cols.0, ..rgo.df.cols.1, row.names=..rgo.df.rowNames)",
+		"colnames(..rgo.df.result) <- ..rgo.df.colNames",
+		"df <- ..rgo.df.result",
+		"plot(x, x)",
+	}
+	if !reflect.DeepEqual(cmds, want) {
+		t.Errorf("expected commands %q, got %q", want, cmds)
+	}
+	b, err := ioutil.ReadFile(filepath.Join(dir, "data-2.json"))
+	if err != nil || string(b) != `["x"]` {
+		t.Errorf("expected data file to contain [\"x\"], got %q (err: %v)", b, err)
+	}
+}
@@ -1,6 +1,7 @@
 package rgo
 
 import (
+	"encoding/json"
 	"io"
 	"net"
 	"net/http"
@@ -24,6 +25,9 @@ type server struct {
 
 	fmu sync.Mutex
 	fwd map[string]chan<- readerDone
+
+	cmu sync.Mutex
+	cbs map[string]Callback
 }
 
 func (s *server) putData(key string, val []byte) {
@@ -50,6 +54,48 @@ func (s *server) rmFwd(key string) {
 	delete(s.fwd, key)
 }
 
+func (s *server) putCallback(key string, fn Callback) {
+	defer s.cmu.Unlock()
+	s.cmu.Lock()
+	s.cbs[key] = fn
+}
+
+func (s *server) rmCallback(key string) {
+	defer s.cmu.Unlock()
+	s.cmu.Lock()
+	delete(s.cbs, key)
+}
+
+type callbackResult struct {
+	Result []float64 `json:"result"`
+	Error  string    `json:"error"`
+}
+
+// serveCallback runs the callback registered under key with
+// the arguments in body and writes the result as JSON.
+func (s *server) serveCallback(w http.ResponseWriter, key string, body io.Reader) {
+	s.cmu.Lock()
+	fn, ok := s.cbs[key]
+	s.cmu.Unlock()
+	if !ok {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	var args []float64
+	var cr callbackResult
+	if err := json.NewDecoder(body).Decode(&args); err != nil {
+		cr.Error = "invalid callback arguments: " + err.Error()
+	} else if res, err := fn(args); err != nil {
+		cr.Error = err.Error()
+	} else {
+		cr.Result = res
+	}
+	if cr.Result == nil {
+		cr.Result = []float64{}
+	}
+	json.NewEncoder(w).Encode(&cr)
+}
+
 func (s *server) httpHandler(w http.ResponseWriter, r *http.Request) {
 	path := strings.TrimLeft(r.URL.Path, "/")
 	if r.Method == "GET" {
@@ -77,6 +123,10 @@ func (s *server) httpHandler(w http.ResponseWriter, r *http.Request) {
 		<-done
 		r.Body.Close()
 		return
+	} else if r.Method == "POST" {
+		s.serveCallback(w, path, r.Body)
+		r.Body.Close()
+		return
 	}
 	http.Error(w, "", http.StatusMethodNotAllowed)
 }
@@ -85,6 +135,7 @@ func newServer() (*server, error) {
 	var s server
 	s.data = make(map[string][]byte)
 	s.fwd = make(map[string]chan<- readerDone)
+	s.cbs = make(map[string]Callback)
 	hs := http.Server{
 		Addr:    ":0",
 		Handler: http.HandlerFunc(s.httpHandler),
@@ -2,9 +2,12 @@ package rgo
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strings"
 	"testing"
 )
 
@@ -99,3 +102,53 @@ func TestServerFwd(t *testing.T) {
 
 	s.s.Stop()
 }
+
+func TestServerCallback(t *testing.T) {
+	s := startTestServer(t)
+	defer s.s.Stop()
+	s.putCallback("go.cb.sum", func(args []float64) ([]float64, error) {
+		if len(args) == 0 {
+			return nil, errors.New("no arguments")
+		}
+		var sum float64
+		for _, v := range args {
+			sum += v
+		}
+		return []float64{sum}, nil
+	})
+	url := fmt.Sprintf("http://localhost:%d/go.cb.sum", s.port)
+	testCases := []struct {
+		Body   string
+		Result []float64
+		Error  string // prefix of the expected error
+	}{
+		{"[1, 2, 3.5]", []float64{6.5}, ""},
+		{"[]", []float64{}, "no arguments"},
+		{"[\"NA\"]", []float64{}, "invalid callback arguments: "},
+	}
+	for i, c := range testCases {
+		resp, err := http.Post(url, "application/json", bytes.NewBufferString(c.Body))
+		if err != nil {
+			t.Fatalf("case %d: unexpected error POST-ing args: %v", i, err)
+		}
+		var got callbackResult
+		err = json.NewDecoder(resp.Body).Decode(&got)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatalf("case %d: error decoding result: %v", i, err)
+		}
+		if fmt.Sprint(got.Result) != fmt.Sprint(c.Result) || !strings.HasPrefix(got.Error, c.Error) || (c.Error == "") != (got.Error == "") {
+			t.Errorf("case %d: expected (%v, %q), got (%v, %q)", i, c.Result, c.Error, got.Result, got.Error)
+		}
+	}
+
+	s.rmCallback("go.cb.sum")
+	resp, err := http.Post(url, "application/json", bytes.NewBufferString("[1]"))
+	if err != nil {
+		t.Fatalf("unexpected error POST-ing args: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected not found after removing callback, got %v", resp.Status)
+	}
+}
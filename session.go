@@ -0,0 +1,202 @@
+package rgo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Version is the version of R and the platform it was built for.
+type Version struct {
+	Major    int
+	Minor    int
+	Patch    int
+	Platform string
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// parseVersion parses the major and minor fields of R.version,
+// e.g. "3" and "4.1".
+func parseVersion(major, minor, platform string) (Version, error) {
+	v := Version{Platform: platform}
+	var err error
+	if v.Major, err = strconv.Atoi(major); err != nil {
+		return v, errors.Errorf("invalid major version %q", major)
+	}
+	parts := strings.SplitN(minor, ".", 2)
+	if v.Minor, err = strconv.Atoi(parts[0]); err != nil {
+		return v, errors.Errorf("invalid minor version %q", minor)
+	}
+	if len(parts) > 1 {
+		if v.Patch, err = strconv.Atoi(parts[1]); err != nil {
+			return v, errors.Errorf("invalid minor version %q", minor)
+		}
+	}
+	return v, nil
+}
+
+// Version returns the version of R used by the Conn.
+func (c *Conn) Version() (Version, error) {
+	err := c.R("..rgo.version <- c(R.version$major, R.version$minor, R.version$platform)")
+	if err != nil {
+		return Version{}, errors.Wrap(err, "failed to find R version")
+	}
+	var v []string
+	if err := c.Get(&v, "..rgo.version"); err != nil {
+		return Version{}, errors.Wrap(err, "failed to get R version")
+	}
+	if len(v) != 3 {
+		return Version{}, errors.Errorf("invalid R version: %v", v)
+	}
+	return parseVersion(v[0], v[1], v[2])
+}
+
+const pkgVersionStr = `..rgo.pkgversion <- tryCatch(as.character(packageVersion(%q)), error=function(e) "")`
+
+// PackageVersion returns the version of the installed R package
+// name. An error is returned if the package is not installed, but
+// unlike other errors, it does not affect later operations.
+func (c *Conn) PackageVersion(name string) (string, error) {
+	if err := c.Rf(pkgVersionStr, name); err != nil {
+		return "", errors.Wrapf(err, "failed to find version of %q", name)
+	}
+	var v []string
+	if err := c.Get(&v, "..rgo.pkgversion"); err != nil {
+		return "", errors.Wrapf(err, "failed to get version of %q", name)
+	}
+	if len(v) != 1 || v[0] == "" {
+		return "", errors.Errorf("package %q is not installed", name)
+	}
+	return v[0], nil
+}
+
+// Package is an R package and its version.
+type Package struct {
+	Name    string
+	Version string
+}
+
+// SessionInfo describes the R session, as reported by sessionInfo().
+type SessionInfo struct {
+	R Version
+	// Running describes the operating system, e.g. "Ubuntu 16.04 LTS".
+	Running string
+	// Locale maps locale categories (e.g. LC_CTYPE) to their values.
+	Locale map[string]string
+	// BLAS and LAPACK are the paths of the linked libraries. They are
+	// empty if R does not report them.
+	BLAS   string
+	LAPACK string
+
+	BasePackages []string
+	// Attached are the attached packages that are not base packages.
+	Attached []Package
+	// Loaded are packages that are loaded but not attached.
+	Loaded []Package
+}
+
+const sessionInfoStr = `..rgo.sessioninfo <- local({
+	s <- sessionInfo()
+	str <- function(x) as.character(if (is.null(x)) "" else x)
+	pkgs <- function(l) list(
+		name=as.character(names(l)),
+		version=as.character(vapply(l, function(p) p$Version, "")))
+	list(
+		version=c(R.version$major, R.version$minor, R.version$platform),
+		running=str(s$running),
+		locale=str(s$locale),
+		blas=str(s$BLAS),
+		lapack=str(s$LAPACK),
+		base=as.character(s$basePkgs),
+		attached=pkgs(s$otherPkgs),
+		loaded=pkgs(s$loadedOnly))
+})`
+
+type packagesJSON struct {
+	Name    []string `json:"name"`
+	Version []string `json:"version"`
+}
+
+func (p packagesJSON) packages() []Package {
+	pkgs := make([]Package, len(p.Name))
+	for i := range p.Name {
+		pkgs[i].Name = p.Name[i]
+		if i < len(p.Version) {
+			pkgs[i].Version = p.Version[i]
+		}
+	}
+	return pkgs
+}
+
+type sessionInfoJSON struct {
+	Version  []string     `json:"version"`
+	Running  []string     `json:"running"`
+	Locale   []string     `json:"locale"`
+	BLAS     []string     `json:"blas"`
+	LAPACK   []string     `json:"lapack"`
+	Base     []string     `json:"base"`
+	Attached packagesJSON `json:"attached"`
+	Loaded   packagesJSON `json:"loaded"`
+}
+
+// SessionInfo describes the R session used by the Conn.
+func (c *Conn) SessionInfo() (*SessionInfo, error) {
+	if err := c.R(sessionInfoStr); err != nil {
+		return nil, errors.Wrap(err, "failed to collect session info")
+	}
+	var sj sessionInfoJSON
+	if err := c.Get(&sj, "..rgo.sessioninfo"); err != nil {
+		return nil, errors.Wrap(err, "failed to get session info")
+	}
+	if len(sj.Version) != 3 {
+		return nil, errors.Errorf("invalid R version: %v", sj.Version)
+	}
+	v, err := parseVersion(sj.Version[0], sj.Version[1], sj.Version[2])
+	if err != nil {
+		return nil, err
+	}
+	return &SessionInfo{
+		R:            v,
+		Running:      first(sj.Running),
+		Locale:       parseLocale(first(sj.Locale)),
+		BLAS:         first(sj.BLAS),
+		LAPACK:       first(sj.LAPACK),
+		BasePackages: sj.Base,
+		Attached:     sj.Attached.packages(),
+		Loaded:       sj.Loaded.packages(),
+	}, nil
+}
+
+func first(s []string) string {
+	if len(s) == 0 {
+		return ""
+	}
+	return s[0]
+}
+
+// parseLocale parses a locale as returned by Sys.getlocale(), e.g.
+// "LC_CTYPE=en_US.UTF-8;LC_NUMERIC=C". A locale that is the same for
+// all categories (e.g. "C") is returned as LC_ALL.
+func parseLocale(s string) map[string]string {
+	m := make(map[string]string)
+	if s == "" {
+		return m
+	}
+	if !strings.Contains(s, "=") {
+		m["LC_ALL"] = s
+		return m
+	}
+	for _, kv := range strings.Split(s, ";") {
+		i := strings.Index(kv, "=")
+		if i < 0 {
+			continue
+		}
+		m[kv[:i]] = kv[i+1:]
+	}
+	return m
+}
@@ -0,0 +1,79 @@
+package rgo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	testCases := []struct {
+		Major, Minor string
+		Want         Version
+		Err          bool
+	}{
+		{"3", "4.1", Version{3, 4, 1, "p"}, false},
+		{"4", "0", Version{4, 0, 0, "p"}, false},
+		{"x", "4.1", Version{}, true},
+		{"3", "4.y", Version{}, true},
+	}
+	for i, c := range testCases {
+		v, err := parseVersion(c.Major, c.Minor, "p")
+		if (err != nil) != c.Err {
+			t.Errorf("case %d: expected error: %t, got %v", i, c.Err, err)
+		}
+		if err == nil && v != c.Want {
+			t.Errorf("case %d: expected %+v, got %+v", i, c.Want, v)
+		}
+	}
+}
+
+func TestParseLocale(t *testing.T) {
+	testCases := []struct {
+		In   string
+		Want map[string]string
+	}{
+		{"", map[string]string{}},
+		{"C", map[string]string{"LC_ALL": "C"}},
+		{"LC_CTYPE=en_US.UTF-8;LC_NUMERIC=C", map[string]string{"LC_CTYPE": "en_US.UTF-8", "LC_NUMERIC": "C"}},
+	}
+	for _, c := range testCases {
+		if got := parseLocale(c.In); !reflect.DeepEqual(got, c.Want) {
+			t.Errorf("parseLocale(%q): expected %v, got %v", c.In, c.Want, got)
+		}
+	}
+}
+
+func TestSessionInfo(t *testing.T) {
+	c := newTestConn(t)
+	defer c.Close()
+
+	v, err := c.Version()
+	if err != nil {
+		t.Fatalf("failed to get R version: %v", err)
+	}
+	if v.Major < 3 || v.Platform == "" {
+		t.Errorf("unexpected R version %+v", v)
+	}
+	if _, err := c.PackageVersion("jsonlite"); err != nil {
+		t.Errorf("failed to get jsonlite version: %v", err)
+	}
+	if _, err := c.PackageVersion("rgo.no.such.package"); err == nil {
+		t.Errorf("expected error getting version of missing package")
+	}
+	si, err := c.SessionInfo()
+	if err != nil {
+		t.Fatalf("failed to get session info: %v", err)
+	}
+	if si.R != v {
+		t.Errorf("expected R version %+v, got %+v", v, si.R)
+	}
+	found := false
+	for _, p := range si.Attached {
+		if p.Name == "jsonlite" && p.Version != "" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected jsonlite to be attached, got %v", si.Attached)
+	}
+}
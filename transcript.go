@@ -0,0 +1,156 @@
+package rgo
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// TranscriptFile is the name of the script written by WithTranscript.
+const TranscriptFile = "transcript.R"
+
+const (
+	commandMarker = "## rgo command "
+
+	scriptHeader = `# Generated by rgo. Run from this directory with
+#   Rscript %s
+library(jsonlite)
+if (!exists("..rgo.dir")) {
+	..rgo.dir <- "."
+}
+`
+
+	readDataStr = "%s = fromJSON(file.path(..rgo.dir, %q))"
+)
+
+// scriptWriter writes R commands into a script and the data they
+// need into files alongside it.
+type scriptWriter struct {
+	dir   string
+	f     *os.File
+	w     *bufio.Writer
+	ncmd  int
+	ndata int
+}
+
+func newScriptWriter(dir, name string) (*scriptWriter, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.Wrap(err, "unable to create script directory")
+	}
+	f, err := os.Create(filepath.Join(dir, name))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create script")
+	}
+	w := &scriptWriter{dir: dir, f: f, w: bufio.NewWriter(f)}
+	fmt.Fprintf(w.w, scriptHeader, name)
+	return w, nil
+}
+
+// command appends cmd to the script. If err is non-nil, it is
+// recorded as a comment after the command.
+func (w *scriptWriter) command(cmd string, err error) error {
+	w.ncmd++
+	fmt.Fprintf(w.w, "\n%s%d\n%s\n", commandMarker, w.ncmd, cmd)
+	if err != nil {
+		kind := "error"
+		if IsWarning(err) {
+			kind = "warning"
+		}
+		msg := strings.Replace(err.Error(), "\n", "\n#   ", -1)
+		fmt.Fprintf(w.w, "# rgo %s: %s\n", kind, msg)
+	}
+	return errors.Wrap(w.w.Flush(), "unable to write script")
+}
+
+// data writes b into a new file and returns the command that reads
+// it into the variable name.
+func (w *scriptWriter) data(b []byte, name string) (string, error) {
+	fname := fmt.Sprintf("data-%d.json", w.ndata)
+	w.ndata++
+	err := ioutil.WriteFile(filepath.Join(w.dir, fname), b, 0644)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to write data file")
+	}
+	return fmt.Sprintf(readDataStr, name, fname), nil
+}
+
+func (w *scriptWriter) close() error {
+	err := w.w.Flush()
+	if err2 := w.f.Close(); err == nil {
+		err = err2
+	}
+	return errors.Wrap(err, "unable to write script")
+}
+
+// WithTranscript records every command run by the Conn into
+// TranscriptFile in dir. Data transferred using Send and SendDF is
+// written into files in dir, so the transcript is a self-contained,
+// reproducible R script. Data retrieved using Get is not recorded.
+//
+// Use Replay to run a transcript with a fresh Conn.
+func WithTranscript(dir string) ConnOption {
+	return func(c *connConfig) {
+		c.transcript = dir
+	}
+}
+
+// Replay runs the commands recorded in the transcript in dir using
+// c. c should be a fresh Conn. Replay stops at the first command
+// that fails and returns its error.
+func Replay(c *Conn, dir string) error {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return errors.Wrap(err, "unable to find transcript directory")
+	}
+	f, err := os.Open(filepath.Join(abs, TranscriptFile))
+	if err != nil {
+		return errors.Wrap(err, "unable to open transcript")
+	}
+	defer f.Close()
+	cmds, err := readScript(f)
+	if err != nil {
+		return err
+	}
+	if err := c.Rf("..rgo.dir <- %q", abs); err != nil {
+		return errors.Wrap(err, "failed to set transcript directory")
+	}
+	for i, cmd := range cmds {
+		if err := c.R(cmd); err != nil && !IsWarning(err) {
+			return errors.Wrapf(err, "command %d failed", i+1)
+		}
+	}
+	return nil
+}
+
+// readScript returns the commands in a script written by a
+// scriptWriter. The header is skipped.
+func readScript(f *os.File) ([]string, error) {
+	var cmds []string
+	var cur []string
+	inCmd := false
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line := s.Text()
+		if strings.HasPrefix(line, commandMarker) {
+			if inCmd {
+				cmds = append(cmds, strings.TrimSpace(strings.Join(cur, "\n")))
+			}
+			cur = cur[:0]
+			inCmd = true
+			continue
+		}
+		cur = append(cur, line)
+	}
+	if err := s.Err(); err != nil {
+		return nil, errors.Wrap(err, "unable to read transcript")
+	}
+	if inCmd {
+		cmds = append(cmds, strings.TrimSpace(strings.Join(cur, "\n")))
+	}
+	return cmds, nil
+}
@@ -0,0 +1,87 @@
+package rgo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestScriptWriter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rgo-transcript")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	w, err := newScriptWriter(dir, TranscriptFile)
+	if err != nil {
+		t.Fatalf("failed to create script writer: %v", err)
+	}
+	read, err := w.data([]byte("[1,2,3]"), "x")
+	if err != nil {
+		t.Fatalf("failed to write data: %v", err)
+	}
+	cmds := []string{read, "y <- x * 2\nprint(y)", "warning('w')", "stop('e')"}
+	errs := []error{nil, nil, rWarning("w"), rError("e\ntraceback")}
+	for i := range cmds {
+		if err := w.command(cmds[i], errs[i]); err != nil {
+			t.Fatalf("failed to write command %d: %v", i, err)
+		}
+	}
+	if err := w.close(); err != nil {
+		t.Fatalf("failed to close script: %v", err)
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "data-0.json"))
+	if err != nil || string(b) != "[1,2,3]" {
+		t.Errorf("expected data file to contain [1,2,3], got %q (err: %v)", b, err)
+	}
+	f, err := os.Open(filepath.Join(dir, TranscriptFile))
+	if err != nil {
+		t.Fatalf("failed to open script: %v", err)
+	}
+	defer f.Close()
+	got, err := readScript(f)
+	if err != nil {
+		t.Fatalf("failed to read script: %v", err)
+	}
+	want := []string{
+		`x = fromJSON(file.path(..rgo.dir, "data-0.json"))`,
+		"y <- x * 2\nprint(y)",
+		"warning('w')\n# rgo warning: w",
+		"stop('e')\n# rgo error: e\n#   traceback",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected commands %q, got %q", want, got)
+	}
+}
+
+func TestTranscriptReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rgo-transcript")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := newTestConn(t, WithTranscript(dir))
+	c.Send([]float64{1, 2, 3}, "x")
+	c.R("y <- sum(x * 2)")
+	if err := c.Close(); err != nil {
+		t.Fatalf("failed to close connection: %v", err)
+	}
+
+	rc := newTestConn(t)
+	defer rc.Close()
+	if err := Replay(rc, dir); err != nil {
+		t.Fatalf("failed to replay transcript: %v", err)
+	}
+	var y []float64
+	if err := rc.Get(&y, "y"); err != nil {
+		t.Fatalf("couldn't get 'y': %v", err)
+	}
+	if len(y) != 1 || y[0] != 12 {
+		t.Errorf("expected [12], got %v", y)
+	}
+}